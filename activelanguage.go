@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type ActiveLanguage struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newActiveLanguage(db *pgxpool.Pool) *ActiveLanguage {
+func newActiveLanguage(db *Pool) *ActiveLanguage {
 	return &ActiveLanguage{
 		db,
 	}
@@ -21,7 +20,7 @@ func (l ActiveLanguage) Schema() string {
 }
 
 func (c *ActiveLanguage) Get(ctx context.Context, guildId uint64) (language string, e error) {
-	if err := c.QueryRow(ctx, `SELECT "language" from active_language WHERE "guild_id" = $1`, guildId).Scan(&language); err != nil && err != pgx.ErrNoRows {
+	if err := c.QueryRow(ctx, `SELECT "language" from active_language WHERE "guild_id" = $1`, guildId).Scan(&language); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type ArchiveChannel struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newArchiveChannel(db *pgxpool.Pool) *ArchiveChannel {
+func newArchiveChannel(db *Pool) *ArchiveChannel {
 	return &ArchiveChannel{
 		db,
 	}
@@ -28,7 +27,7 @@ CREATE TABLE IF NOT EXISTS archive_channel(
 func (c *ArchiveChannel) Get(ctx context.Context, guildId uint64) (archiveChannel *uint64, e error) {
 	query := `SELECT "channel_id" from archive_channel WHERE "guild_id" = $1;`
 
-	if err := c.QueryRow(ctx, query, guildId).Scan(&archiveChannel); err != nil && err != pgx.ErrNoRows {
+	if err := c.QueryRow(ctx, query, guildId).Scan(&archiveChannel); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
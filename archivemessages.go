@@ -3,8 +3,7 @@ package database
 import (
 	"context"
 	_ "embed"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type ArchiveMessage struct {
@@ -13,10 +12,10 @@ type ArchiveMessage struct {
 }
 
 type ArchiveMessages struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newArchiveMessages(db *pgxpool.Pool) *ArchiveMessages {
+func newArchiveMessages(db *Pool) *ArchiveMessages {
 	return &ArchiveMessages{
 		db,
 	}
@@ -46,7 +45,7 @@ func (a *ArchiveMessages) Get(ctx context.Context, guildId uint64, ticketId int)
 	var data ArchiveMessage
 	err := a.QueryRow(ctx, archiveMessagesGet, guildId, ticketId).Scan(&data.ChannelId, &data.MessageId)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return ArchiveMessage{}, false, nil
 		} else {
 			return ArchiveMessage{}, false, err
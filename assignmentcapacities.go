@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+)
+
+// AssignmentCapacities stores the maximum number of open tickets each staff member can be assigned by the
+// assignment policies at once. Members without a capacity use the policy's default capacity.
+type AssignmentCapacities struct {
+	*Pool
+}
+
+func newAssignmentCapacities(db *Pool) *AssignmentCapacities {
+	return &AssignmentCapacities{
+		db,
+	}
+}
+
+func (AssignmentCapacities) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS assignment_capacities(
+	"guild_id" int8 NOT NULL,
+	"user_id" int8 NOT NULL,
+	"max_open_tickets" int NOT NULL,
+	CONSTRAINT assignment_capacities_max_open_tickets_non_negative CHECK ("max_open_tickets" >= 0),
+	PRIMARY KEY("guild_id", "user_id")
+);
+`
+}
+
+func (c *AssignmentCapacities) Get(ctx context.Context, guildId, userId uint64) (capacity *int, e error) {
+	query := `SELECT "max_open_tickets" FROM assignment_capacities WHERE "guild_id" = $1 AND "user_id" = $2;`
+	if err := c.QueryRow(ctx, query, guildId, userId).Scan(&capacity); err != nil && !errors.Is(err, ErrNotFound) {
+		e = err
+	}
+
+	return
+}
+
+func (c *AssignmentCapacities) GetAll(ctx context.Context, guildId uint64) (map[uint64]int, error) {
+	query := `SELECT "user_id", "max_open_tickets" FROM assignment_capacities WHERE "guild_id" = $1;`
+
+	rows, err := c.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	capacities := make(map[uint64]int)
+	for rows.Next() {
+		var userId uint64
+		var capacity int
+		if err := rows.Scan(&userId, &capacity); err != nil {
+			return nil, err
+		}
+
+		capacities[userId] = capacity
+	}
+
+	return capacities, rows.Err()
+}
+
+func (c *AssignmentCapacities) Set(ctx context.Context, guildId, userId uint64, maxOpenTickets int) (err error) {
+	query := `
+INSERT INTO assignment_capacities("guild_id", "user_id", "max_open_tickets")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "user_id") DO UPDATE SET "max_open_tickets" = $3;`
+
+	_, err = c.Exec(ctx, query, guildId, userId, maxOpenTickets)
+	return
+}
+
+func (c *AssignmentCapacities) Delete(ctx context.Context, guildId, userId uint64) (err error) {
+	_, err = c.Exec(ctx, `DELETE FROM assignment_capacities WHERE "guild_id" = $1 AND "user_id" = $2;`, guildId, userId)
+	return
+}
@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"math/rand"
+)
+
+type AssignmentStrategy string
+
+const (
+	// AssignmentStrategyRoundRobin assigns tickets to each eligible member in turn, ordered by user ID
+	AssignmentStrategyRoundRobin AssignmentStrategy = "round_robin"
+	// AssignmentStrategyLeastOpen assigns tickets to the eligible member with the fewest open claimed tickets
+	AssignmentStrategyLeastOpen AssignmentStrategy = "least_open"
+	// AssignmentStrategyRandomOnCall assigns tickets to a random eligible member who is on call
+	AssignmentStrategyRandomOnCall AssignmentStrategy = "random_on_call"
+)
+
+// AssignmentPolicy controls how tickets are assigned automatically, for tickets opened from a panel or handled by a
+// support team. Exactly one of PanelId and TeamId is set.
+//
+// Panel policies pick from the members of the panel's support teams, and from the users with support or admin
+// permissions if the panel uses the default team. Team policies pick from the team's members. If OnlyOnCall is set, or
+// the strategy is random_on_call, only members who are on call are eligible. Members who have reached their capacity
+// in AssignmentCapacities, or DefaultCapacity if they have none, are not eligible.
+type AssignmentPolicy struct {
+	Id                 int                `json:"id"`
+	GuildId            uint64             `json:"guild_id,string"`
+	PanelId            *int               `json:"panel_id"`
+	TeamId             *int               `json:"team_id"`
+	Strategy           AssignmentStrategy `json:"strategy"`
+	OnlyOnCall         bool               `json:"only_on_call"`
+	DefaultCapacity    *int               `json:"default_capacity"`
+	LastAssignedUserId *uint64            `json:"last_assigned_user_id,string"`
+}
+
+type assignmentCandidate struct {
+	userId      uint64
+	openTickets int
+}
+
+type AssignmentPolicies struct {
+	*Pool
+	claims *TicketClaims
+}
+
+var (
+	//go:embed sql/assignment_policies/schema.sql
+	assignmentPoliciesSchema string
+
+	//go:embed sql/assignment_policies/get_candidates.sql
+	assignmentPoliciesGetCandidates string
+)
+
+const assignmentPolicyColumns = `"id", "guild_id", "panel_id", "team_id", "strategy", "only_on_call", "default_capacity", "last_assigned_user_id"`
+
+func newAssignmentPolicies(db *Pool, claims *TicketClaims) *AssignmentPolicies {
+	return &AssignmentPolicies{
+		db,
+		claims,
+	}
+}
+
+func (AssignmentPolicies) Schema() string {
+	return assignmentPoliciesSchema
+}
+
+func (p *AssignmentPolicies) Get(ctx context.Context, guildId uint64, policyId int) (policy AssignmentPolicy, err error) {
+	query := `SELECT ` + assignmentPolicyColumns + ` FROM assignment_policies WHERE "id" = $1 AND "guild_id" = $2;`
+	err = p.QueryRow(ctx, query, policyId, guildId).Scan(policy.fieldPtrs()...)
+	return
+}
+
+func (p *AssignmentPolicies) GetByPanel(ctx context.Context, panelId int) (policy AssignmentPolicy, err error) {
+	query := `SELECT ` + assignmentPolicyColumns + ` FROM assignment_policies WHERE "panel_id" = $1;`
+	err = p.QueryRow(ctx, query, panelId).Scan(policy.fieldPtrs()...)
+	return
+}
+
+func (p *AssignmentPolicies) GetByTeam(ctx context.Context, teamId int) (policy AssignmentPolicy, err error) {
+	query := `SELECT ` + assignmentPolicyColumns + ` FROM assignment_policies WHERE "team_id" = $1;`
+	err = p.QueryRow(ctx, query, teamId).Scan(policy.fieldPtrs()...)
+	return
+}
+
+func (p *AssignmentPolicies) GetAll(ctx context.Context, guildId uint64) ([]AssignmentPolicy, error) {
+	query := `SELECT ` + assignmentPolicyColumns + ` FROM assignment_policies WHERE "guild_id" = $1 ORDER BY "id";`
+
+	rows, err := p.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	policies := make([]AssignmentPolicy, 0)
+	for rows.Next() {
+		var policy AssignmentPolicy
+		if err := rows.Scan(policy.fieldPtrs()...); err != nil {
+			return nil, err
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, rows.Err()
+}
+
+// Create returns ErrConflict if the panel or team already has a policy
+func (p *AssignmentPolicies) Create(ctx context.Context, policy AssignmentPolicy) (id int, err error) {
+	query := `
+INSERT INTO assignment_policies("guild_id", "panel_id", "team_id", "strategy", "only_on_call", "default_capacity")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING "id";`
+
+	err = p.QueryRow(ctx, query, policy.GuildId, policy.PanelId, policy.TeamId, policy.Strategy, policy.OnlyOnCall, policy.DefaultCapacity).Scan(&id)
+	return
+}
+
+// Update changes the strategy and eligibility rules of the policy. The rotation position is retained.
+func (p *AssignmentPolicies) Update(ctx context.Context, policy AssignmentPolicy) (err error) {
+	query := `
+UPDATE assignment_policies
+SET "strategy" = $3, "only_on_call" = $4, "default_capacity" = $5
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err = p.Exec(ctx, query, policy.Id, policy.GuildId, policy.Strategy, policy.OnlyOnCall, policy.DefaultCapacity)
+	return
+}
+
+func (p *AssignmentPolicies) Delete(ctx context.Context, guildId uint64, policyId int) (err error) {
+	_, err = p.Exec(ctx, `DELETE FROM assignment_policies WHERE "id" = $1 AND "guild_id" = $2;`, policyId, guildId)
+	return
+}
+
+// Assign picks a member according to the policy and claims the ticket for them, in the same transaction. The policy
+// row is locked while the member is picked, so concurrent assignments advance the rotation in turn. The ticket's opener
+// is never picked. If the ticket is closed, already has a primary assignee, or no member is eligible, ok is false and
+// the ticket is left unchanged.
+func (p *AssignmentPolicies) Assign(ctx context.Context, guildId uint64, ticketId, policyId int) (userId uint64, ok bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := p.Begin(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var policy AssignmentPolicy
+	policyQuery := `SELECT ` + assignmentPolicyColumns + ` FROM assignment_policies WHERE "id" = $1 AND "guild_id" = $2 FOR UPDATE;`
+	if err := tx.QueryRow(ctx, policyQuery, policyId, guildId).Scan(policy.fieldPtrs()...); err != nil {
+		return 0, false, err
+	}
+
+	ticketQuery := `
+SELECT tickets.user_id, tickets.open, EXISTS(
+	SELECT 1
+	FROM ticket_assignments
+	WHERE ticket_assignments.guild_id = tickets.guild_id
+	  AND ticket_assignments.ticket_id = tickets.id
+	  AND ticket_assignments.role = 'primary'
+	  AND ticket_assignments.released_at IS NULL
+)
+FROM tickets
+WHERE tickets.guild_id = $1 AND tickets.id = $2
+FOR NO KEY UPDATE OF tickets;`
+
+	var openerId uint64
+	var open, assigned bool
+	if err := tx.QueryRow(ctx, ticketQuery, guildId, ticketId).Scan(&openerId, &open, &assigned); err != nil {
+		return 0, false, err
+	}
+
+	if !open || assigned {
+		return 0, false, nil
+	}
+
+	onlyOnCall := policy.OnlyOnCall || policy.Strategy == AssignmentStrategyRandomOnCall
+	candidates, err := p.getCandidatesTx(ctx, tx, guildId, policy, openerId, onlyOnCall)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if len(candidates) == 0 {
+		return 0, false, nil
+	}
+
+	// A member may be picked by several policies at once, e.g. by a panel's policy and by a team's, which lock different
+	// rows. Lock the capacity of each candidate in user ID order, then count their open tickets again, so that
+	// concurrent assignments cannot take a member over their capacity.
+	userIds := make([]uint64, len(candidates))
+	for i, candidate := range candidates {
+		userIds[i] = candidate.userId
+	}
+
+	userIdsArray := &pgtype.Int8Array{}
+	if err := userIdsArray.Set(userIds); err != nil {
+		return 0, false, err
+	}
+
+	lockQuery := `
+SELECT pg_advisory_xact_lock(hashtext('assignment_capacity:' || $1::int8::text || ':' || candidates.user_id::text))
+FROM (SELECT UNNEST($2::int8[]) AS user_id ORDER BY 1) AS candidates;`
+
+	if _, err := tx.Exec(ctx, lockQuery, guildId, userIdsArray); err != nil {
+		return 0, false, err
+	}
+
+	candidates, err = p.getCandidatesTx(ctx, tx, guildId, policy, openerId, onlyOnCall)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if len(candidates) == 0 {
+		return 0, false, nil
+	}
+
+	userId = policy.pick(candidates)
+
+	if err := p.claims.SetTx(ctx, tx, guildId, ticketId, userId); err != nil {
+		return 0, false, err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE assignment_policies SET "last_assigned_user_id" = $2 WHERE "id" = $1;`, policy.Id, userId); err != nil {
+		return 0, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, false, err
+	}
+
+	return userId, true, nil
+}
+
+// getCandidatesTx returns the members that the policy may assign, with the number of open tickets each is assigned,
+// ordered by user ID
+func (p *AssignmentPolicies) getCandidatesTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	guildId uint64,
+	policy AssignmentPolicy,
+	openerId uint64,
+	onlyOnCall bool,
+) ([]assignmentCandidate, error) {
+	rows, err := tx.Query(ctx, assignmentPoliciesGetCandidates, guildId, policy.TeamId, policy.PanelId, openerId, onlyOnCall, policy.DefaultCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var candidates []assignmentCandidate
+	for rows.Next() {
+		var candidate assignmentCandidate
+		if err := rows.Scan(&candidate.userId, &candidate.openTickets); err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// pick chooses a member from the candidates, which are ordered by user ID
+func (p AssignmentPolicy) pick(candidates []assignmentCandidate) uint64 {
+	switch p.Strategy {
+	case AssignmentStrategyLeastOpen:
+		chosen := candidates[0]
+		for _, candidate := range candidates[1:] {
+			if candidate.openTickets < chosen.openTickets {
+				chosen = candidate
+			}
+		}
+
+		return chosen.userId
+	case AssignmentStrategyRandomOnCall:
+		return candidates[rand.Intn(len(candidates))].userId
+	default:
+		// Round-robin: the member after the last assigned member, wrapping around to the start
+		if p.LastAssignedUserId != nil {
+			for _, candidate := range candidates {
+				if candidate.userId > *p.LastAssignedUserId {
+					return candidate.userId
+				}
+			}
+		}
+
+		return candidates[0].userId
+	}
+}
+
+func (p *AssignmentPolicy) fieldPtrs() []interface{} {
+	return []interface{}{
+		&p.Id,
+		&p.GuildId,
+		&p.PanelId,
+		&p.TeamId,
+		&p.Strategy,
+		&p.OnlyOnCall,
+		&p.DefaultCapacity,
+		&p.LastAssignedUserId,
+	}
+}
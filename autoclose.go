@@ -2,13 +2,18 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	_ "embed"
+	"errors"
 	"time"
 )
 
 type AutoCloseTable struct {
-	*pgxpool.Pool
+	*Pool
+
+	// IsPremium reports whether a guild has premium, and must be set before calling GetTicketsDueForClose. The guild's
+	// owner is not stored in the database, so premium status is resolved by the caller, normally using
+	// Entitlements.GetGuildTiers.
+	IsPremium func(ctx context.Context, guildId uint64) (bool, error)
 }
 
 type AutoCloseSettings struct {
@@ -18,9 +23,39 @@ type AutoCloseSettings struct {
 	OnUserLeave             *bool          `json:"on_user_leave"`
 }
 
-func newAutoCloseTable(db *pgxpool.Pool) *AutoCloseTable {
+type AutoCloseReason string
+
+const (
+	AutoCloseReasonNoResponse AutoCloseReason = "no_response"
+	AutoCloseReasonInactive   AutoCloseReason = "inactive"
+)
+
+// AutoCloseCandidate is an open ticket that should be closed by auto-close. NoResponse candidates have had no messages
+// since they were opened, while Inactive candidates are waiting on a reply to a staff message.
+type AutoCloseCandidate struct {
+	GuildId   uint64          `json:"guild_id,string"`
+	TicketId  int             `json:"ticket_id"`
+	ChannelId *uint64         `json:"channel_id,string"`
+	PanelId   *int            `json:"panel_id"`
+	Reason    AutoCloseReason `json:"reason"`
+}
+
+// AutoCloseCursor marks the position of a scan through the open tickets. The zero value starts from the beginning.
+type AutoCloseCursor struct {
+	GuildId  uint64
+	TicketId int
+}
+
+var (
+	//go:embed sql/auto_close/get_tickets_due_for_close.sql
+	autoCloseGetTicketsDueForClose string
+)
+
+var ErrPremiumCheckNotSet = errors.New("auto close premium check not set")
+
+func newAutoCloseTable(db *Pool) *AutoCloseTable {
 	return &AutoCloseTable{
-		db,
+		Pool: db,
 	}
 }
 
@@ -39,7 +74,7 @@ CREATE TABLE IF NOT EXISTS auto_close(
 
 func (a *AutoCloseTable) Get(ctx context.Context, guildId uint64) (settings AutoCloseSettings, e error) {
 	query := `SELECT "enabled", "since_open_with_no_response", "since_last_message", "on_user_leave" FROM auto_close WHERE "guild_id" = $1;`
-	if err := a.QueryRow(ctx, query, guildId).Scan(&settings.Enabled, &settings.SinceOpenWithNoResponse, &settings.SinceLastMessage, &settings.OnUserLeave); err != nil && err != pgx.ErrNoRows { // defaults to nil if no rows
+	if err := a.QueryRow(ctx, query, guildId).Scan(&settings.Enabled, &settings.SinceOpenWithNoResponse, &settings.SinceLastMessage, &settings.OnUserLeave); err != nil && !errors.Is(err, ErrNotFound) { // defaults to nil if no rows
 		e = err
 	}
 
@@ -84,3 +119,69 @@ WHERE "guild_id" = $1;
 	_, err = a.Exec(ctx, query, guildId)
 	return
 }
+
+// GetTicketsDueForClose returns the open tickets that are due to be auto-closed among the next batchSize candidates,
+// ordered by guild and ticket ID, starting after cursor. The returned cursor should be passed to the next call, and is
+// the zero value once the scan is complete.
+//
+// Per-panel settings in PanelAutoClose take precedence over the guild's settings, excluded tickets are skipped, and
+// only guilds for which IsPremium returns true are included, so fewer than batchSize tickets may be returned before the
+// scan is complete. Closing tickets when the opener leaves the guild is handled by the member leave event instead.
+func (a *AutoCloseTable) GetTicketsDueForClose(ctx context.Context, batchSize int, cursor AutoCloseCursor) ([]AutoCloseCandidate, AutoCloseCursor, error) {
+	if a.IsPremium == nil {
+		return nil, cursor, ErrPremiumCheckNotSet
+	}
+
+	rows, err := a.Query(ctx, autoCloseGetTicketsDueForClose, batchSize, cursor.GuildId, cursor.TicketId)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	defer rows.Close()
+
+	candidates := make([]AutoCloseCandidate, 0, batchSize)
+	for rows.Next() {
+		var candidate AutoCloseCandidate
+		if err := rows.Scan(&candidate.GuildId, &candidate.TicketId, &candidate.ChannelId, &candidate.PanelId, &candidate.Reason); err != nil {
+			return nil, cursor, err
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, cursor, err
+	}
+
+	rows.Close()
+
+	next := AutoCloseCursor{}
+	if len(candidates) == batchSize {
+		last := candidates[len(candidates)-1]
+		next = AutoCloseCursor{
+			GuildId:  last.GuildId,
+			TicketId: last.TicketId,
+		}
+	}
+
+	// Each guild is only checked once per batch
+	premium := make(map[uint64]bool)
+	filtered := candidates[:0]
+	for _, candidate := range candidates {
+		isPremium, ok := premium[candidate.GuildId]
+		if !ok {
+			isPremium, err = a.IsPremium(ctx, candidate.GuildId)
+			if err != nil {
+				return nil, cursor, err
+			}
+
+			premium[candidate.GuildId] = isPremium
+		}
+
+		if isPremium {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	return filtered, next, nil
+}
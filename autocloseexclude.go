@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type AutoCloseExclude struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newAutoCloseExclude(db *pgxpool.Pool) *AutoCloseExclude {
+func newAutoCloseExclude(db *Pool) *AutoCloseExclude {
 	return &AutoCloseExclude{
 		db,
 	}
@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type Blacklist struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newBlacklist(db *pgxpool.Pool) *Blacklist {
+func newBlacklist(db *Pool) *Blacklist {
 	return &Blacklist{
 		db,
 	}
@@ -37,12 +34,13 @@ LIMIT $2
 OFFSET $3;`
 
 	rows, err := b.Query(ctx, query, guildId, limit, offset)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userId uint64
 		if err := rows.Scan(&userId); err != nil {
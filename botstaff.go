@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type BotStaff struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newBotStaff(db *pgxpool.Pool) *BotStaff {
+func newBotStaff(db *Pool) *BotStaff {
 	return &BotStaff{
 		db,
 	}
@@ -0,0 +1,305 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BusinessCalendar defines when a guild, or one of its support teams if TeamId is set, is staffed. Hours are given in
+// Timezone, and no time on a holiday counts as business time.
+type BusinessCalendar struct {
+	Id       int                     `json:"id"`
+	GuildId  uint64                  `json:"guild_id,string"`
+	TeamId   *int                    `json:"team_id"`
+	Timezone string                  `json:"timezone"`
+	Hours    []BusinessHoursInterval `json:"hours"`
+	Holidays []BusinessHoliday       `json:"holidays"`
+}
+
+// BusinessHoursInterval is a period of business hours on a day of the week, from StartMinute up to EndMinute minutes
+// past midnight
+type BusinessHoursInterval struct {
+	Weekday     time.Weekday `json:"weekday"`
+	StartMinute int          `json:"start_minute"`
+	EndMinute   int          `json:"end_minute"`
+}
+
+type BusinessHoliday struct {
+	Date time.Time `json:"date"`
+	Name *string   `json:"name"`
+}
+
+// Duration returns the amount of business time between from and to
+func (c BusinessCalendar) Duration(from, to time.Time) (time.Duration, error) {
+	clock, err := c.clock()
+	if err != nil {
+		return 0, err
+	}
+
+	return clock.duration(from, to), nil
+}
+
+// businessClock measures business time for a calendar. The timezone is loaded and the hours and holidays indexed once,
+// so that many periods can be measured against the same calendar cheaply.
+type businessClock struct {
+	location *time.Location
+	hours    [7][]BusinessHoursInterval
+	holidays map[string]bool
+}
+
+func (c BusinessCalendar) clock() (businessClock, error) {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return businessClock{}, fmt.Errorf("%w: %s", ErrInvalidTimezone, c.Timezone)
+	}
+
+	clock := businessClock{
+		location: location,
+		holidays: make(map[string]bool, len(c.Holidays)),
+	}
+
+	for _, interval := range c.Hours {
+		clock.hours[interval.Weekday] = append(clock.hours[interval.Weekday], interval)
+	}
+
+	for _, holiday := range c.Holidays {
+		clock.holidays[holiday.Date.Format(dateLayout)] = true
+	}
+
+	return clock, nil
+}
+
+func (c businessClock) duration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	var total time.Duration
+	localFrom := from.In(c.location)
+	for day := time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, c.location); day.Before(to); day = day.AddDate(0, 0, 1) {
+		if c.holidays[day.Format(dateLayout)] {
+			continue
+		}
+
+		for _, interval := range c.hours[day.Weekday()] {
+			start := time.Date(day.Year(), day.Month(), day.Day(), 0, interval.StartMinute, 0, 0, c.location)
+			end := time.Date(day.Year(), day.Month(), day.Day(), 0, interval.EndMinute, 0, 0, c.location)
+			if start.Before(from) {
+				start = from
+			}
+
+			if end.After(to) {
+				end = to
+			}
+
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+
+	return total
+}
+
+type BusinessHours struct {
+	*Pool
+}
+
+func newBusinessHours(db *Pool) *BusinessHours {
+	return &BusinessHours{
+		db,
+	}
+}
+
+func (BusinessHours) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS business_hours_calendars(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"team_id" int DEFAULT NULL,
+	"timezone" VARCHAR(64) NOT NULL,
+	FOREIGN KEY("team_id") REFERENCES support_team("id") ON DELETE CASCADE ON UPDATE CASCADE,
+	UNIQUE NULLS NOT DISTINCT("guild_id", "team_id"),
+	PRIMARY KEY("id")
+);
+
+CREATE TABLE IF NOT EXISTS business_hours_intervals(
+	"calendar_id" int NOT NULL,
+	"weekday" int2 NOT NULL,
+	"start_minute" int2 NOT NULL,
+	"end_minute" int2 NOT NULL,
+	CONSTRAINT business_hours_intervals_weekday_range CHECK ("weekday" >= 0 AND "weekday" <= 6),
+	CONSTRAINT business_hours_intervals_minute_range CHECK ("start_minute" >= 0 AND "end_minute" <= 1440 AND "end_minute" > "start_minute"),
+	FOREIGN KEY("calendar_id") REFERENCES business_hours_calendars("id") ON DELETE CASCADE,
+	PRIMARY KEY("calendar_id", "weekday", "start_minute")
+);
+
+CREATE TABLE IF NOT EXISTS business_hours_holidays(
+	"calendar_id" int NOT NULL,
+	"date" date NOT NULL,
+	"name" VARCHAR(64) DEFAULT NULL,
+	FOREIGN KEY("calendar_id") REFERENCES business_hours_calendars("id") ON DELETE CASCADE,
+	PRIMARY KEY("calendar_id", "date")
+);
+`
+}
+
+// Get returns the calendar of the team, or of the guild if teamId is nil. ErrNotFound is returned if there is none.
+func (b *BusinessHours) Get(ctx context.Context, guildId uint64, teamId *int) (BusinessCalendar, error) {
+	query := `SELECT "id", "guild_id", "team_id", "timezone" FROM business_hours_calendars WHERE "guild_id" = $1 AND "team_id" IS NOT DISTINCT FROM $2;`
+
+	calendar := BusinessCalendar{
+		Hours:    make([]BusinessHoursInterval, 0),
+		Holidays: make([]BusinessHoliday, 0),
+	}
+
+	if err := b.QueryRow(ctx, query, guildId, teamId).Scan(&calendar.Id, &calendar.GuildId, &calendar.TeamId, &calendar.Timezone); err != nil {
+		return BusinessCalendar{}, err
+	}
+
+	hoursQuery := `
+SELECT "weekday", "start_minute", "end_minute"
+FROM business_hours_intervals
+WHERE "calendar_id" = $1
+ORDER BY "weekday", "start_minute";`
+
+	rows, err := b.Query(ctx, hoursQuery, calendar.Id)
+	if err != nil {
+		return BusinessCalendar{}, err
+	}
+
+	for rows.Next() {
+		var interval BusinessHoursInterval
+		if err := rows.Scan(&interval.Weekday, &interval.StartMinute, &interval.EndMinute); err != nil {
+			rows.Close()
+			return BusinessCalendar{}, err
+		}
+
+		calendar.Hours = append(calendar.Hours, interval)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return BusinessCalendar{}, err
+	}
+
+	rows, err = b.Query(ctx, `SELECT "date", "name" FROM business_hours_holidays WHERE "calendar_id" = $1 ORDER BY "date";`, calendar.Id)
+	if err != nil {
+		return BusinessCalendar{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var holiday BusinessHoliday
+		if err := rows.Scan(&holiday.Date, &holiday.Name); err != nil {
+			return BusinessCalendar{}, err
+		}
+
+		calendar.Holidays = append(calendar.Holidays, holiday)
+	}
+
+	return calendar, rows.Err()
+}
+
+// Set creates or replaces the calendar of the team, or of the guild if TeamId is nil, including its hours and
+// holidays. An error wrapping ErrInvalidTimezone is returned if the timezone is not a valid IANA timezone.
+func (b *BusinessHours) Set(ctx context.Context, calendar BusinessCalendar) (id int, err error) {
+	if _, err := time.LoadLocation(calendar.Timezone); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTimezone, calendar.Timezone)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := b.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO business_hours_calendars("guild_id", "team_id", "timezone")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "team_id") DO UPDATE SET "timezone" = $3
+RETURNING "id";`
+
+	if err := tx.QueryRow(ctx, query, calendar.GuildId, calendar.TeamId, calendar.Timezone).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM business_hours_intervals WHERE "calendar_id" = $1;`, id); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM business_hours_holidays WHERE "calendar_id" = $1;`, id); err != nil {
+		return 0, err
+	}
+
+	for _, interval := range calendar.Hours {
+		query := `INSERT INTO business_hours_intervals("calendar_id", "weekday", "start_minute", "end_minute") VALUES($1, $2, $3, $4);`
+		if _, err := tx.Exec(ctx, query, id, int(interval.Weekday), interval.StartMinute, interval.EndMinute); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, holiday := range calendar.Holidays {
+		query := `INSERT INTO business_hours_holidays("calendar_id", "date", "name") VALUES($1, $2::date, $3);`
+		if _, err := tx.Exec(ctx, query, id, holiday.Date.Format(dateLayout), holiday.Name); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (b *BusinessHours) Delete(ctx context.Context, guildId uint64, teamId *int) (err error) {
+	_, err = b.Exec(ctx, `DELETE FROM business_hours_calendars WHERE "guild_id" = $1 AND "team_id" IS NOT DISTINCT FROM $2;`, guildId, teamId)
+	return
+}
+
+// averageBusinessDuration runs a query selecting a start and end time in each row, and averages the business time
+// between them. Each row is measured as it is read, so the periods are never held in memory together. It returns nil if
+// the query returns no rows.
+func averageBusinessDuration(ctx context.Context, pool *Pool, calendar BusinessCalendar, query string, args ...interface{}) (*time.Duration, error) {
+	clock, err := calendar.clock()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var total time.Duration
+	var count int
+	for rows.Next() {
+		var from, to time.Time
+		if err := rows.Scan(&from, &to); err != nil {
+			return nil, err
+		}
+
+		total += clock.duration(from, to)
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	average := total / time.Duration(count)
+	return &average, nil
+}
@@ -4,12 +4,11 @@ import (
 	"context"
 	_ "embed"
 	"github.com/TicketsBot/common/model"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type CategoryUpdateQueue struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type CategoryUpdateQueueItem struct {
@@ -31,7 +30,7 @@ var (
 	categoryUpdateQueueGetReadyForUpdate string
 )
 
-func newCategoryUpdateQueueTable(db *pgxpool.Pool) *CategoryUpdateQueue {
+func newCategoryUpdateQueueTable(db *Pool) *CategoryUpdateQueue {
 	return &CategoryUpdateQueue{
 		db,
 	}
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type ChannelCategory struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newChannelCategory(db *pgxpool.Pool) *ChannelCategory {
+func newChannelCategory(db *Pool) *ChannelCategory {
 	return &ChannelCategory{
 		db,
 	}
@@ -26,7 +25,7 @@ CREATE TABLE IF NOT EXISTS channel_category(
 }
 
 func (c *ChannelCategory) Get(ctx context.Context, guildId uint64) (channelCategory uint64, e error) {
-	if err := c.QueryRow(ctx, `SELECT "category_id" from channel_category WHERE "guild_id" = $1;`, guildId).Scan(&channelCategory); err != nil && err != pgx.ErrNoRows {
+	if err := c.QueryRow(ctx, `SELECT "category_id" from channel_category WHERE "guild_id" = $1;`, guildId).Scan(&channelCategory); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
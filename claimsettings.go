@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type ClaimSettings struct {
@@ -17,10 +16,10 @@ var defaultClaimSettings = ClaimSettings{
 }
 
 type ClaimSettingsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newClaimSettingsTable(db *pgxpool.Pool) *ClaimSettingsTable {
+func newClaimSettingsTable(db *Pool) *ClaimSettingsTable {
 	return &ClaimSettingsTable{
 		db,
 	}
@@ -40,7 +39,7 @@ CREATE TABLE IF NOT EXISTS claim_settings(
 func (c *ClaimSettingsTable) Get(ctx context.Context, guildId uint64) (settings ClaimSettings, e error) {
 	query := `SELECT "support_can_view", "support_can_type" FROM claim_settings WHERE "guild_id" = $1;`
 	if err := c.QueryRow(ctx, query, guildId).Scan(&settings.SupportCanView, &settings.SupportCanType); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			settings = defaultClaimSettings
 		} else {
 			e = err
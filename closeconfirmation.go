@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type CloseConfirmation struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newCloseConfirmation(db *pgxpool.Pool) *CloseConfirmation {
+func newCloseConfirmation(db *Pool) *CloseConfirmation {
 	return &CloseConfirmation{
 		db,
 	}
@@ -27,7 +26,7 @@ CREATE TABLE IF NOT EXISTS close_confirmation(
 
 func (c *CloseConfirmation) Get(ctx context.Context, guildId uint64) (confirm bool, e error) {
 	if err := c.QueryRow(ctx, `SELECT "confirm" from close_confirmation WHERE "guild_id" = $1;`, guildId).Scan(&confirm); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			confirm = true
 		} else {
 			e = err
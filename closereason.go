@@ -2,9 +2,9 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CloseMetadata struct {
@@ -13,10 +13,10 @@ type CloseMetadata struct {
 }
 
 type CloseMetadataTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newCloseReasonTable(db *pgxpool.Pool) *CloseMetadataTable {
+func newCloseReasonTable(db *Pool) *CloseMetadataTable {
 	return &CloseMetadataTable{
 		db,
 	}
@@ -44,7 +44,7 @@ WHERE "guild_id" = $1 AND "ticket_id" = $2;
 
 	var data CloseMetadata
 	if err := c.QueryRow(ctx, query, guildId, ticketId).Scan(&data.Reason, &data.ClosedBy); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return CloseMetadata{}, false, nil
 		} else {
 			return CloseMetadata{}, false, err
@@ -85,14 +85,19 @@ WHERE "guild_id" = $1 AND "ticket_id" = ANY($2);
 	return ticketMetadata, nil
 }
 
-func (c *CloseMetadataTable) Set(ctx context.Context, guildId uint64, ticketId int, data CloseMetadata) (err error) {
-	query := `
+const closeMetadataSet = `
 INSERT INTO close_reason("guild_id", "ticket_id", "close_reason", "closed_by")
 VALUES($1, $2, $3, $4)
 ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "close_reason" = $3, "closed_by" = $4;
 `
 
-	_, err = c.Exec(ctx, query, guildId, ticketId, data.Reason, data.ClosedBy)
+func (c *CloseMetadataTable) Set(ctx context.Context, guildId uint64, ticketId int, data CloseMetadata) (err error) {
+	_, err = c.Exec(ctx, closeMetadataSet, guildId, ticketId, data.Reason, data.ClosedBy)
+	return
+}
+
+func (c *CloseMetadataTable) SetTx(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId int, data CloseMetadata) (err error) {
+	_, err = tx.Exec(ctx, closeMetadataSet, guildId, ticketId, data.Reason, data.ClosedBy)
 	return
 }
 
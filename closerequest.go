@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 	"time"
 )
 
@@ -16,10 +15,10 @@ type CloseRequest struct {
 }
 
 type CloseRequestTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newCloseRequestTable(db *pgxpool.Pool) *CloseRequestTable {
+func newCloseRequestTable(db *Pool) *CloseRequestTable {
 	return &CloseRequestTable{
 		db,
 	}
@@ -52,7 +51,7 @@ WHERE "guild_id" = $1 AND "ticket_id" = $2;
 
 	if err == nil {
 		return request, true, nil
-	} else if err == pgx.ErrNoRows {
+	} else if errors.Is(err, ErrNotFound) {
 		return request, false, nil
 	} else {
 		return request, false, err
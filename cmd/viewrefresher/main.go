@@ -2,39 +2,95 @@ package main
 
 import (
 	"context"
+	"errors"
 	"github.com/TicketsBot/database"
 	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
+const (
+	defaultHttpAddr     = ":8080"
+	shutdownGracePeriod = time.Second * 30
+)
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
 	logrus.Info("Connecting to database...")
 	pool := must(pgxpool.Connect(ctx, os.Getenv("DATABASE_URI")))
+	defer pool.Close()
+
 	db := database.NewDatabase(pool)
 	logrus.Info("Connected!")
 
-	if os.Getenv("DAEMON") == "true" {
-		for {
-			doRefresh(db)
-			time.Sleep(6 * time.Hour)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	m := newMetrics(registry, pool)
+
+	scheduler := db.NewViewScheduler()
+	scheduler.ShutdownGracePeriod = shutdownGracePeriod
+	scheduler.OnRefresh = func(view database.View, duration time.Duration, err error) {
+		m.observe(view, duration, err)
+
+		if err != nil {
+			logrus.Errorf("Error refreshing view %s after %s: %s", view.Name(), duration, err.Error())
+		} else {
+			logrus.Infof("Refreshed view %s in %s", view.Name(), duration)
 		}
-	} else {
-		doRefresh(db)
 	}
-}
 
-func doRefresh(db *database.Database) {
-	logrus.Info("Starting refresh...")
+	if os.Getenv("DAEMON") != "true" {
+		logrus.Info("Starting refresh...")
+		if err := scheduler.RefreshAll(ctx); err != nil {
+			logrus.Errorf("Error refreshing views: %s", err.Error())
+		}
+
+		logrus.Info("Refresh complete")
+		return
+	}
 
-	for _, view := range db.Views() {
-		if err := view.Refresh(); err != nil {
-			logrus.Errorf("Error refreshing view: %s", err.Error())
+	if err := m.seed(ctx, db.ViewRefreshes); err != nil {
+		logrus.Errorf("Error loading previous refresh times: %s", err.Error())
+	}
+
+	httpAddr := os.Getenv("HTTP_ADDR")
+	if httpAddr == "" {
+		httpAddr = defaultHttpAddr
+	}
+
+	srv := newServer(httpAddr, pool, registry)
+	go func() {
+		logrus.Infof("Starting HTTP server on %s", httpAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("HTTP server stopped: %s", err.Error())
 		}
+	}()
+
+	logrus.Info("Starting scheduler...")
+	if err := scheduler.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		logrus.Errorf("Scheduler stopped: %s", err.Error())
+	}
+
+	// Run only returns once any in-progress refresh has completed or been cancelled
+	logrus.Info("Shutting down...")
+	srv.markShuttingDown()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logrus.Errorf("Error shutting down HTTP server: %s", err.Error())
 	}
 
-	logrus.Info("Refresh complete")
+	logrus.Info("Shutdown complete")
 }
 
 func must[T any](v T, err error) T {
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"github.com/TicketsBot/database"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+type metrics struct {
+	refreshDuration *prometheus.HistogramVec
+	refreshFailures *prometheus.CounterVec
+	lastSuccess     *prometheus.GaugeVec
+}
+
+func newMetrics(registry prometheus.Registerer, pool *pgxpool.Pool) *metrics {
+	m := &metrics{
+		refreshDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "viewrefresher",
+			Name:      "refresh_duration_seconds",
+			Help:      "Time taken to refresh each materialized view, including failed attempts.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+		}, []string{"view"}),
+		refreshFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "viewrefresher",
+			Name:      "refresh_failures_total",
+			Help:      "Number of failed refreshes of each materialized view.",
+		}, []string{"view"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "viewrefresher",
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful refresh of each materialized view.",
+		}, []string{"view"}),
+	}
+
+	registry.MustRegister(m.refreshDuration, m.refreshFailures, m.lastSuccess, newPoolCollector(pool))
+	return m
+}
+
+// seed sets the last success gauge from the recorded refresh history, so that it reflects refreshes made before the
+// process started rather than being absent until each view is next refreshed
+func (m *metrics) seed(ctx context.Context, refreshes *database.ViewRefreshes) error {
+	statuses, err := refreshes.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.LastSuccessAt != nil {
+			m.lastSuccess.WithLabelValues(status.ViewName).Set(float64(status.LastSuccessAt.UnixNano()) / 1e9)
+		}
+	}
+
+	return nil
+}
+
+// observe is used as the ViewScheduler.OnRefresh callback
+func (m *metrics) observe(view database.View, duration time.Duration, err error) {
+	m.refreshDuration.WithLabelValues(view.Name()).Observe(duration.Seconds())
+
+	if err != nil {
+		m.refreshFailures.WithLabelValues(view.Name()).Inc()
+	} else {
+		m.lastSuccess.WithLabelValues(view.Name()).SetToCurrentTime()
+	}
+}
+
+// poolCollector reports pgxpool.Stat() at scrape time
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("viewrefresher", "pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:                 pool,
+		acquiredConns:        desc("acquired_conns", "Number of currently acquired connections in the pool."),
+		idleConns:            desc("idle_conns", "Number of currently idle connections in the pool."),
+		constructingConns:    desc("constructing_conns", "Number of connections with construction in progress in the pool."),
+		totalConns:           desc("total_conns", "Total number of resources currently in the pool."),
+		maxConns:             desc("max_conns", "Maximum size of the pool."),
+		acquireCount:         desc("acquire_count_total", "Cumulative count of successful acquires from the pool."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Total duration of all successful acquires from the pool."),
+		canceledAcquireCount: desc("canceled_acquire_count_total", "Cumulative count of acquires from the pool that were canceled by a context."),
+		emptyAcquireCount:    desc("empty_acquire_count_total", "Cumulative count of successful acquires that waited for a connection to be released or constructed."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.canceledAcquireCount
+	ch <- c.emptyAcquireCount
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+}
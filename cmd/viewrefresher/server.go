@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const readinessTimeout = time.Second * 3
+
+type server struct {
+	pool         *pgxpool.Pool
+	shuttingDown atomic.Bool
+	httpServer   *http.Server
+}
+
+func newServer(addr string, pool *pgxpool.Pool, gatherer prometheus.Gatherer) *server {
+	s := &server{
+		pool: pool,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/readyz", s.handleReady)
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: time.Second * 5,
+	}
+
+	return s
+}
+
+func (s *server) ListenAndServe() error {
+	return s.httpServer.ListenAndServe()
+}
+
+// markShuttingDown causes /readyz to start failing, while the server continues to serve requests
+func (s *server) markShuttingDown() {
+	s.shuttingDown.Store(true)
+}
+
+func (s *server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// handleHealth reports whether the process is alive
+func (s *server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// handleReady reports whether the process is able to refresh views
+func (s *server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if s.shuttingDown.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := s.pool.Ping(ctx); err != nil {
+		http.Error(w, "database unavailable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
@@ -2,13 +2,12 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CustomIntegrationTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type CustomIntegration struct {
@@ -35,7 +34,7 @@ type CustomIntegrationWithActive struct {
 	Active bool `json:"active"`
 }
 
-func newCustomIntegrationTable(db *pgxpool.Pool) *CustomIntegrationTable {
+func newCustomIntegrationTable(db *Pool) *CustomIntegrationTable {
 	return &CustomIntegrationTable{
 		db,
 	}
@@ -58,6 +57,16 @@ CREATE TABLE IF NOT EXISTS custom_integrations(
 	PRIMARY KEY("id")
 );
 CREATE INDEX IF NOT EXISTS custom_integrations_owner_id ON custom_integrations("owner_id");
+-- Creation times were not recorded before this column was added, so existing integrations are left as NULL
+ALTER TABLE custom_integrations ADD COLUMN IF NOT EXISTS "created_at" timestamptz DEFAULT NULL;
+ALTER TABLE custom_integrations ALTER COLUMN "created_at" SET DEFAULT NOW();
+ALTER TABLE custom_integrations ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('english', "name"), 'A') || setweight(to_tsvector('english', "description"), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS custom_integrations_search_vector ON custom_integrations USING GIN("search_vector");
+ALTER TABLE custom_integrations ADD COLUMN IF NOT EXISTS "category" VARCHAR(32) NULL;
+CREATE INDEX IF NOT EXISTS custom_integrations_category ON custom_integrations("category") WHERE "category" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS custom_integrations_public_approved ON custom_integrations("id") WHERE "public" = 't' AND "approved" = 't';
 `
 }
 
@@ -80,7 +89,7 @@ func (i *CustomIntegrationTable) Get(ctx context.Context, id int) (CustomIntegra
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return integration, false, nil
 		} else {
 			return CustomIntegration{}, false, err
@@ -301,6 +310,14 @@ func (i *CustomIntegrationTable) SetPublic(ctx context.Context, integrationId in
 	return
 }
 
+// SetCategory sets the marketplace category of an integration, which is stored in lowercase. A nil category removes
+// the integration from every category.
+func (i *CustomIntegrationTable) SetCategory(ctx context.Context, integrationId int, category *string) (err error) {
+	query := `UPDATE custom_integrations SET "category" = LOWER($2) WHERE "id" = $1;`
+	_, err = i.Exec(ctx, query, integrationId, category)
+	return
+}
+
 func (i *CustomIntegrationTable) Update(ctx context.Context, integration CustomIntegration) (err error) {
 	query := `
 UPDATE custom_integrations
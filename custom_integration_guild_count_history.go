@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// CustomIntegrationGuildCountHistory stores a daily snapshot of custom_integration_guild_counts, which is captured
+// each time the view is refreshed. It is used to calculate the install delta for the trending sort order.
+type CustomIntegrationGuildCountHistory struct {
+	*Pool
+}
+
+type CustomIntegrationGuildCountSnapshot struct {
+	SnapshotDate time.Time `json:"snapshot_date"`
+	Count        int       `json:"count"`
+}
+
+func newCustomIntegrationGuildCountHistory(db *Pool) *CustomIntegrationGuildCountHistory {
+	return &CustomIntegrationGuildCountHistory{
+		db,
+	}
+}
+
+func (h CustomIntegrationGuildCountHistory) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS custom_integration_guild_count_history(
+	"integration_id" int NOT NULL,
+	"snapshot_date" date NOT NULL,
+	"count" int NOT NULL,
+	FOREIGN KEY("integration_id") REFERENCES custom_integrations("id") ON DELETE CASCADE,
+	PRIMARY KEY("integration_id", "snapshot_date")
+);
+CREATE INDEX IF NOT EXISTS custom_integration_guild_count_history_snapshot_date ON custom_integration_guild_count_history("snapshot_date");
+`
+}
+
+// customIntegrationGuildCountSnapshotStatements returns the statements to capture the current contents of the view, to be executed after it has
+// been refreshed. Snapshots older than 90 days are pruned at the same time.
+func customIntegrationGuildCountSnapshotStatements() []string {
+	return []string{
+		`
+INSERT INTO custom_integration_guild_count_history("integration_id", "snapshot_date", "count")
+SELECT counts.integration_id, CURRENT_DATE, counts.count
+FROM custom_integration_guild_counts AS counts
+INNER JOIN custom_integrations AS integrations ON counts.integration_id = integrations.id
+ON CONFLICT ("integration_id", "snapshot_date") DO UPDATE SET "count" = EXCLUDED.count;`,
+		`DELETE FROM custom_integration_guild_count_history WHERE "snapshot_date" < CURRENT_DATE - INTERVAL '90 days';`,
+	}
+}
+
+func (h *CustomIntegrationGuildCountHistory) Get(ctx context.Context, integrationId int, since time.Time) ([]CustomIntegrationGuildCountSnapshot, error) {
+	query := `
+SELECT "snapshot_date", "count"
+FROM custom_integration_guild_count_history
+WHERE "integration_id" = $1 AND "snapshot_date" >= $2::date
+ORDER BY "snapshot_date" ASC;`
+
+	rows, err := h.Query(ctx, query, integrationId, since)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var snapshots []CustomIntegrationGuildCountSnapshot
+	for rows.Next() {
+		var snapshot CustomIntegrationGuildCountSnapshot
+		if err := rows.Scan(&snapshot.SnapshotDate, &snapshot.Count); err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
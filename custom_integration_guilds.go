@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type CustomIntegrationGuildsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newCustomIntegrationGuildsTable(db *pgxpool.Pool) *CustomIntegrationGuildsTable {
+func newCustomIntegrationGuildsTable(db *Pool) *CustomIntegrationGuildsTable {
 	return &CustomIntegrationGuildsTable{
 		db,
 	}
@@ -3,11 +3,10 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CustomIntegrationHeadersTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type CustomIntegrationHeader struct {
@@ -17,7 +16,7 @@ type CustomIntegrationHeader struct {
 	Value         string `json:"value"`
 }
 
-func newCustomIntegrationHeadersTable(db *pgxpool.Pool) *CustomIntegrationHeadersTable {
+func newCustomIntegrationHeadersTable(db *Pool) *CustomIntegrationHeadersTable {
 	return &CustomIntegrationHeadersTable{
 		db,
 	}
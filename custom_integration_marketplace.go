@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"github.com/jackc/pgtype"
+	"strings"
+	"time"
+)
+
+type CustomIntegrationSortOrder string
+
+const (
+	CustomIntegrationSortPopular  CustomIntegrationSortOrder = "popular"
+	CustomIntegrationSortRecent   CustomIntegrationSortOrder = "recent"
+	CustomIntegrationSortTrending CustomIntegrationSortOrder = "trending"
+)
+
+const (
+	customIntegrationListDefaultLimit = 20
+	customIntegrationListMaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type CustomIntegrationListQuery struct {
+	Search   string   `json:"search"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Limit    int      `json:"limit"`
+}
+
+type CustomIntegrationCategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// CustomIntegrationListing is an integration as shown in the marketplace. CreatedAt is nil for integrations created
+// before creation times were recorded, which are listed last when sorting by most recent.
+type CustomIntegrationListing struct {
+	CustomIntegrationWithGuildCount
+	CreatedAt    *time.Time `json:"created_at"`
+	Category     *string    `json:"category"`
+	InstallDelta int        `json:"install_delta"`
+	Tags         []string   `json:"tags"`
+}
+
+// customIntegrationCursor is the position of the last listing on a page. The sort order is included so that a cursor
+// issued for one sort order cannot be used with another.
+type customIntegrationCursor struct {
+	Sort    CustomIntegrationSortOrder `json:"s"`
+	SortKey int64                      `json:"k"`
+	Id      int                        `json:"i"`
+}
+
+var (
+	//go:embed sql/custom_integrations/list_public.sql
+	customIntegrationsListPublic string
+)
+
+func (c customIntegrationCursor) encode() (string, error) {
+	marshalled, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(marshalled), nil
+}
+
+func decodeCustomIntegrationCursor(encoded string) (customIntegrationCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return customIntegrationCursor{}, ErrInvalidCursor
+	}
+
+	var cursor customIntegrationCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil {
+		return customIntegrationCursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// ListPublic returns a page of public, approved integrations, along with the cursor for the next page. The cursor is an
+// empty string if there are no more results.
+func (i *CustomIntegrationTable) ListPublic(
+	ctx context.Context,
+	query CustomIntegrationListQuery,
+	sort CustomIntegrationSortOrder,
+	cursor string,
+) ([]CustomIntegrationListing, string, error) {
+	switch sort {
+	case CustomIntegrationSortPopular, CustomIntegrationSortRecent, CustomIntegrationSortTrending:
+	case "":
+		sort = CustomIntegrationSortPopular
+	default:
+		return nil, "", errors.New("invalid sort order")
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = customIntegrationListDefaultLimit
+	} else if limit > customIntegrationListMaxLimit {
+		limit = customIntegrationListMaxLimit
+	}
+
+	var after customIntegrationCursor
+	if cursor != "" {
+		var err error
+		after, err = decodeCustomIntegrationCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if after.Sort != sort {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	// Tags are stored in lowercase
+	tags := make([]string, 0, len(query.Tags))
+	for _, tag := range query.Tags {
+		tags = append(tags, strings.ToLower(tag))
+	}
+
+	tagArray := &pgtype.TextArray{}
+	if err := tagArray.Set(tags); err != nil {
+		return nil, "", err
+	}
+
+	// Fetch one extra row to determine whether there is another page
+	rows, err := i.Query(ctx, customIntegrationsListPublic,
+		query.Search,
+		tagArray,
+		sort,
+		cursor != "",
+		after.SortKey,
+		after.Id,
+		limit+1,
+		strings.ToLower(query.Category),
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer rows.Close()
+
+	var listings []CustomIntegrationListing
+	var sortKeys []int64
+	for rows.Next() {
+		var listing CustomIntegrationListing
+		var sortKey int64
+		if err := rows.Scan(
+			&listing.Id,
+			&listing.OwnerId,
+			&listing.WebhookUrl,
+			&listing.ValidationUrl,
+			&listing.HttpMethod,
+			&listing.Name,
+			&listing.Description,
+			&listing.ImageUrl,
+			&listing.PrivacyPolicyUrl,
+			&listing.Public,
+			&listing.Approved,
+			&listing.CreatedAt,
+			&listing.Category,
+			&listing.GuildCount,
+			&listing.InstallDelta,
+			&listing.Tags,
+			&sortKey,
+		); err != nil {
+			return nil, "", err
+		}
+
+		listings = append(listings, listing)
+		sortKeys = append(sortKeys, sortKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(listings) <= limit {
+		return listings, "", nil
+	}
+
+	listings = listings[:limit]
+
+	next, err := customIntegrationCursor{
+		Sort:    sort,
+		SortKey: sortKeys[limit-1],
+		Id:      listings[limit-1].Id,
+	}.encode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return listings, next, nil
+}
+
+// GetPublicCategories returns every category containing at least one public, approved integration, largest first
+func (i *CustomIntegrationTable) GetPublicCategories(ctx context.Context) ([]CustomIntegrationCategoryCount, error) {
+	query := `
+SELECT "category", COUNT(*)
+FROM custom_integrations
+WHERE "public" = 't' AND "approved" = 't' AND "category" IS NOT NULL
+GROUP BY "category"
+ORDER BY COUNT(*) DESC, "category" ASC;`
+
+	rows, err := i.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var categories []CustomIntegrationCategoryCount
+	for rows.Next() {
+		var category CustomIntegrationCategoryCount
+		if err := rows.Scan(&category.Category, &category.Count); err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
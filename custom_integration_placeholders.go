@@ -1,12 +1,9 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type CustomIntegrationPlaceholdersTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type CustomIntegrationPlaceholder struct {
@@ -16,7 +13,7 @@ type CustomIntegrationPlaceholder struct {
 	JsonPath      string `json:"json_path"`
 }
 
-func newCustomIntegrationPlaceholdersTable(db *pgxpool.Pool) *CustomIntegrationPlaceholdersTable {
+func newCustomIntegrationPlaceholdersTable(db *Pool) *CustomIntegrationPlaceholdersTable {
 	return &CustomIntegrationPlaceholdersTable{
 		db,
 	}
@@ -3,11 +3,10 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CustomIntegrationSecretValuesTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type SecretWithValue struct {
@@ -15,7 +14,7 @@ type SecretWithValue struct {
 	Value string `json:"value"`
 }
 
-func newCustomIntegrationSecretValuesTable(db *pgxpool.Pool) *CustomIntegrationSecretValuesTable {
+func newCustomIntegrationSecretValuesTable(db *Pool) *CustomIntegrationSecretValuesTable {
 	return &CustomIntegrationSecretValuesTable{
 		db,
 	}
@@ -3,11 +3,10 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CustomIntegrationSecretsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type CustomIntegrationSecret struct {
@@ -17,7 +16,7 @@ type CustomIntegrationSecret struct {
 	Description   *string `json:"description"`
 }
 
-func newCustomIntegrationSecretsTable(db *pgxpool.Pool) *CustomIntegrationSecretsTable {
+func newCustomIntegrationSecretsTable(db *Pool) *CustomIntegrationSecretsTable {
 	return &CustomIntegrationSecretsTable{
 		db,
 	}
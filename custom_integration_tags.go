@@ -0,0 +1,109 @@
+package database
+
+import "context"
+
+type CustomIntegrationTagsTable struct {
+	*Pool
+}
+
+type CustomIntegrationTagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+func newCustomIntegrationTagsTable(db *Pool) *CustomIntegrationTagsTable {
+	return &CustomIntegrationTagsTable{
+		db,
+	}
+}
+
+func (i CustomIntegrationTagsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS custom_integration_tags(
+	"integration_id" int NOT NULL,
+	"tag" VARCHAR(32) NOT NULL,
+	FOREIGN KEY("integration_id") REFERENCES custom_integrations("id") ON DELETE CASCADE,
+	PRIMARY KEY("integration_id", "tag")
+);
+CREATE INDEX IF NOT EXISTS custom_integration_tags_tag ON custom_integration_tags("tag");
+`
+}
+
+func (i *CustomIntegrationTagsTable) Get(ctx context.Context, integrationId int) ([]string, error) {
+	query := `SELECT "tag" FROM custom_integration_tags WHERE "integration_id" = $1 ORDER BY "tag";`
+
+	rows, err := i.Query(ctx, query, integrationId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetPublicTags returns every tag used by at least one public, approved integration, most used first
+func (i *CustomIntegrationTagsTable) GetPublicTags(ctx context.Context) ([]CustomIntegrationTagCount, error) {
+	query := `
+SELECT tags.tag, COUNT(*)
+FROM custom_integration_tags AS tags
+INNER JOIN custom_integrations AS integrations ON tags.integration_id = integrations.id
+WHERE integrations.public = 't' AND integrations.approved = 't'
+GROUP BY tags.tag
+ORDER BY COUNT(*) DESC, tags.tag ASC;`
+
+	rows, err := i.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var tags []CustomIntegrationTagCount
+	for rows.Next() {
+		var tag CustomIntegrationTagCount
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (i *CustomIntegrationTagsTable) Set(ctx context.Context, integrationId int, tags []string) error {
+	tx, err := i.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM custom_integration_tags WHERE "integration_id" = $1;`, integrationId); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		query := `
+INSERT INTO custom_integration_tags("integration_id", "tag")
+VALUES ($1, LOWER($2))
+ON CONFLICT ("integration_id", "tag") DO NOTHING;`
+
+		if _, err := tx.Exec(ctx, query, integrationId, tag); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
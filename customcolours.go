@@ -2,15 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CustomColours struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newCustomColours(db *pgxpool.Pool) *CustomColours {
+func newCustomColours(db *Pool) *CustomColours {
 	return &CustomColours{
 		db,
 	}
@@ -32,7 +32,7 @@ func (c *CustomColours) Get(ctx context.Context, guildId uint64, colourId int16)
 	if err := c.QueryRow(ctx, query, guildId, colourId).Scan(&colourCode); err == nil {
 		ok = true
 	} else {
-		if err != pgx.ErrNoRows {
+		if !errors.Is(err, ErrNotFound) {
 			e = err
 		}
 	}
@@ -4,15 +4,14 @@ import (
 	"context"
 	_ "embed"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type DashboardUsersTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newDashboardUsersTable(db *pgxpool.Pool) *DashboardUsersTable {
+func newDashboardUsersTable(db *Pool) *DashboardUsersTable {
 	return &DashboardUsersTable{
 		db,
 	}
@@ -10,14 +10,17 @@ import (
 const defaultTransactionTimeout = time.Second * 3
 
 type Database struct {
-	pool                           *pgxpool.Pool
+	pool                           *Pool
 	ActiveLanguage                 *ActiveLanguage
 	ArchiveChannel                 *ArchiveChannel
 	ArchiveMessages                *ArchiveMessages
+	AssignmentCapacities           *AssignmentCapacities
+	AssignmentPolicies             *AssignmentPolicies
 	AutoClose                      *AutoCloseTable
 	AutoCloseExclude               *AutoCloseExclude
 	Blacklist                      *Blacklist
 	BotStaff                       *BotStaff
+	BusinessHours                  *BusinessHours
 	CategoryUpdateQueue            *CategoryUpdateQueue
 	ChannelCategory                *ChannelCategory
 	ClaimSettings                  *ClaimSettingsTable
@@ -26,11 +29,13 @@ type Database struct {
 	CloseRequest                   *CloseRequestTable
 	CustomIntegrations             *CustomIntegrationTable
 	CustomIntegrationGuildCounts   *CustomIntegrationGuildCountsView
+	CustomIntegrationHistory       *CustomIntegrationGuildCountHistory
 	CustomIntegrationGuilds        *CustomIntegrationGuildsTable
 	CustomIntegrationHeaders       *CustomIntegrationHeadersTable
 	CustomIntegrationPlaceholders  *CustomIntegrationPlaceholdersTable
 	CustomIntegrationSecretValues  *CustomIntegrationSecretValuesTable
 	CustomIntegrationSecrets       *CustomIntegrationSecretsTable
+	CustomIntegrationTags          *CustomIntegrationTagsTable
 	CustomColours                  *CustomColours
 	DashboardUsers                 *DashboardUsersTable
 	DiscordEntitlements            *DiscordEntitlements
@@ -38,23 +43,31 @@ type Database struct {
 	EmbedFields                    *EmbedFieldsTable
 	Embeds                         *EmbedsTable
 	Entitlements                   *Entitlements
+	EscalationPolicies             *EscalationPolicies
 	ExitSurveyResponses            *ExitSurveyResponses
 	FeedbackEnabled                *FeedbackEnabled
 	FirstResponseTime              *FirstResponseTime
 	FormInput                      *FormInputTable
+	FormInputConditions            *FormInputConditions
 	Forms                          *FormsTable
 	GlobalBlacklist                *GlobalBlacklist
 	GuildLeaveTime                 *GuildLeaveTime
 	GuildMetadata                  *GuildMetadataTable
 	LegacyPremiumEntitlementGuilds *LegacyPremiumEntitlementGuilds
 	LegacyPremiumEntitlements      *LegacyPremiumEntitlements
+	Macros                         *Macros
 	MultiPanels                    *MultiPanelTable
 	MultiPanelTargets              *MultiPanelTargets
 	MultiServerSkus                *MultiServerSkus
 	NamingScheme                   *TicketNamingScheme
 	OnCall                         *OnCall
+	OnCallHolidays                 *OnCallHolidays
+	OnCallOverrides                *OnCallOverrides
+	OnCallSchedules                *OnCallSchedules
 	Panel                          *PanelTable
 	PanelAccessControlRules        *PanelAccessControlRules
+	PanelAutoClose                 *PanelAutoClose
+	PanelCustomFields              *PanelCustomFields
 	PanelRoleMentions              *PanelRoleMentions
 	PanelTeams                     *PanelTeamsTable
 	PanelUserMention               *PanelUserMention
@@ -65,24 +78,40 @@ type Database struct {
 	PremiumKeys                    *PremiumKeys
 	RoleBlacklist                  *RoleBlacklist
 	RolePermissions                *RolePermissions
+	ScheduledTicketActions         *ScheduledTicketActions
 	ServerBlacklist                *ServerBlacklist
 	ServiceRatings                 *ServiceRatings
 	Settings                       *SettingsTable
+	SlaTargets                     *SlaTargets
 	StaffOverride                  *StaffOverride
 	SubscriptionSkus               *SubscriptionSkus
 	SupportTeam                    *SupportTeamTable
 	SupportTeamMembers             *SupportTeamMembersTable
 	SupportTeamRoles               *SupportTeamRolesTable
 	Tag                            *TagsTable
+	TagAliases                     *TagAliases
+	TagCategories                  *TagCategories
+	TagUsages                      *TagUsages
+	TicketAssignments              *TicketAssignments
 	TicketClaims                   *TicketClaims
+	TicketCustomFieldValues        *TicketCustomFieldValues
+	TicketFormResponses            *TicketFormResponses
+	TicketIdCounters               *TicketIdCounters
+	TicketLabels                   *TicketLabels
+	TicketLabelAssignments         *TicketLabelAssignments
 	TicketLastMessage              *TicketLastMessageTable
 	TicketLimit                    *TicketLimit
+	TicketLimitRules               *TicketLimitRules
+	TicketLinks                    *TicketLinks
 	TicketMembers                  *TicketMembers
+	TicketNotes                    *TicketNotes
 	TicketPermissions              *TicketPermissionsTable
+	TicketReopens                  *TicketReopens
 	Tickets                        *TicketTable
 	UsedKeys                       *UsedKeys
 	UsersCanClose                  *UsersCanClose
 	UserGuilds                     *UserGuildsTable
+	ViewRefreshes                  *ViewRefreshes
 	VoteCredits                    *VoteCredits
 	Votes                          *Votes
 	Webhooks                       *WebhookTable
@@ -94,29 +123,43 @@ type Database struct {
 	WhitelabelUsers                *WhitelabelUsers
 }
 
-func NewDatabase(pool *pgxpool.Pool) *Database {
+func NewDatabase(rawPool *pgxpool.Pool, opts ...Option) *Database {
+	pool := newPool(rawPool, opts...)
+
+	// Tables used by other tables are shared, rather than constructed separately for each
+	ticketIdCounters := newTicketIdCounters(pool)
+	tickets := newTicketTable(pool, ticketIdCounters)
+	closeReason := newCloseReasonTable(pool)
+	ticketAssignments := newTicketAssignments(pool)
+	ticketClaims := newTicketClaims(pool, ticketAssignments)
+
 	db := &Database{
 		pool:                           pool,
 		ActiveLanguage:                 newActiveLanguage(pool),
 		ArchiveChannel:                 newArchiveChannel(pool),
 		ArchiveMessages:                newArchiveMessages(pool),
+		AssignmentCapacities:           newAssignmentCapacities(pool),
+		AssignmentPolicies:             newAssignmentPolicies(pool, ticketClaims),
 		AutoClose:                      newAutoCloseTable(pool),
 		AutoCloseExclude:               newAutoCloseExclude(pool),
 		Blacklist:                      newBlacklist(pool),
 		BotStaff:                       newBotStaff(pool),
+		BusinessHours:                  newBusinessHours(pool),
 		CategoryUpdateQueue:            newCategoryUpdateQueueTable(pool),
 		ChannelCategory:                newChannelCategory(pool),
 		ClaimSettings:                  newClaimSettingsTable(pool),
 		CloseConfirmation:              newCloseConfirmation(pool),
-		CloseReason:                    newCloseReasonTable(pool),
+		CloseReason:                    closeReason,
 		CloseRequest:                   newCloseRequestTable(pool),
 		CustomIntegrations:             newCustomIntegrationTable(pool),
 		CustomIntegrationGuildCounts:   newCustomIntegrationGuildCountsView(pool),
+		CustomIntegrationHistory:       newCustomIntegrationGuildCountHistory(pool),
 		CustomIntegrationGuilds:        newCustomIntegrationGuildsTable(pool),
 		CustomIntegrationHeaders:       newCustomIntegrationHeadersTable(pool),
 		CustomIntegrationPlaceholders:  newCustomIntegrationPlaceholdersTable(pool),
 		CustomIntegrationSecretValues:  newCustomIntegrationSecretValuesTable(pool),
 		CustomIntegrationSecrets:       newCustomIntegrationSecretsTable(pool),
+		CustomIntegrationTags:          newCustomIntegrationTagsTable(pool),
 		CustomColours:                  newCustomColours(pool),
 		DashboardUsers:                 newDashboardUsersTable(pool),
 		DiscordEntitlements:            newDiscordEntitlementsTable(pool),
@@ -124,23 +167,31 @@ func NewDatabase(pool *pgxpool.Pool) *Database {
 		EmbedFields:                    newEmbedFieldsTable(pool),
 		Embeds:                         newEmbedsTable(pool),
 		Entitlements:                   newEntitlementsTable(pool),
+		EscalationPolicies:             newEscalationPolicies(pool),
 		ExitSurveyResponses:            newExitSurveyResponses(pool),
 		FeedbackEnabled:                newFeedbackEnabled(pool),
 		FirstResponseTime:              newFirstResponseTime(pool),
 		FormInput:                      newFormInputTable(pool),
+		FormInputConditions:            newFormInputConditions(pool),
 		Forms:                          newFormsTable(pool),
 		GlobalBlacklist:                newGlobalBlacklist(pool),
 		GuildLeaveTime:                 newGuildLeaveTime(pool),
 		GuildMetadata:                  newGuildMetadataTable(pool),
 		LegacyPremiumEntitlementGuilds: newLegacyPremiumEntitlementGuildsTable(pool),
 		LegacyPremiumEntitlements:      newLegacyPremiumEntitlement(pool),
+		Macros:                         newMacros(pool),
 		MultiPanels:                    newMultiMultiPanelTable(pool),
 		MultiPanelTargets:              newMultiPanelTargets(pool),
 		MultiServerSkus:                newMultiServerSkusTable(pool),
 		NamingScheme:                   newTicketNamingScheme(pool),
 		OnCall:                         newOnCall(pool),
+		OnCallHolidays:                 newOnCallHolidays(pool),
+		OnCallOverrides:                newOnCallOverrides(pool),
+		OnCallSchedules:                newOnCallSchedules(pool),
 		Panel:                          newPanelTable(pool),
 		PanelAccessControlRules:        newPanelAccessControlRules(pool),
+		PanelAutoClose:                 newPanelAutoClose(pool),
+		PanelCustomFields:              newPanelCustomFields(pool),
 		PanelRoleMentions:              newPanelRoleMentions(pool),
 		PanelTeams:                     newPanelTeamsTable(pool),
 		PanelUserMention:               newPanelUserMention(pool),
@@ -151,24 +202,40 @@ func NewDatabase(pool *pgxpool.Pool) *Database {
 		PremiumKeys:                    newPremiumKeys(pool),
 		RoleBlacklist:                  newRoleBlacklist(pool),
 		RolePermissions:                newRolePermissions(pool),
+		ScheduledTicketActions:         newScheduledTicketActions(pool),
 		ServerBlacklist:                newServerBlacklist(pool),
 		ServiceRatings:                 newServiceRatings(pool),
 		Settings:                       newSettingsTable(pool),
+		SlaTargets:                     newSlaTargets(pool),
 		StaffOverride:                  newStaffOverride(pool),
 		SubscriptionSkus:               newSubscriptionSkusTable(pool),
 		SupportTeam:                    newSupportTeamTable(pool),
 		SupportTeamMembers:             newSupportTeamMembersTable(pool),
 		SupportTeamRoles:               newSupportTeamRolesTable(pool),
 		Tag:                            newTag(pool),
-		TicketClaims:                   newTicketClaims(pool),
+		TagAliases:                     newTagAliases(pool),
+		TagCategories:                  newTagCategories(pool),
+		TagUsages:                      newTagUsages(pool),
+		TicketAssignments:              ticketAssignments,
+		TicketClaims:                   ticketClaims,
+		TicketCustomFieldValues:        newTicketCustomFieldValues(pool),
+		TicketFormResponses:            newTicketFormResponses(pool),
+		TicketIdCounters:               ticketIdCounters,
+		TicketLabels:                   newTicketLabels(pool),
+		TicketLabelAssignments:         newTicketLabelAssignments(pool),
 		TicketLastMessage:              newTicketLastMessageTable(pool),
 		TicketLimit:                    newTicketLimit(pool),
+		TicketLimitRules:               newTicketLimitRules(pool),
+		TicketLinks:                    newTicketLinks(pool, tickets, closeReason),
 		TicketMembers:                  newTicketMembers(pool),
+		TicketNotes:                    newTicketNotes(pool),
 		TicketPermissions:              newTicketPermissionsTable(pool),
-		Tickets:                        newTicketTable(pool),
+		TicketReopens:                  newTicketReopens(pool, tickets),
+		Tickets:                        tickets,
 		UsedKeys:                       newUsedKeys(pool),
 		UsersCanClose:                  newUsersCanClose(pool),
 		UserGuilds:                     newUserGuildsTable(pool),
+		ViewRefreshes:                  newViewRefreshes(pool),
 		VoteCredits:                    newVoteCreditsTable(pool),
 		Votes:                          newVotes(pool),
 		Webhooks:                       newWebhookTable(pool),
@@ -210,6 +277,7 @@ func (d *Database) WithTx(ctx context.Context, f func(tx pgx.Tx) error) error {
 func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 	mustCreate(ctx, pool,
 		d.ActiveLanguage,
+		d.AssignmentCapacities,
 		d.ArchiveChannel,
 		d.AutoClose,
 		d.Blacklist,
@@ -220,10 +288,12 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.CustomIntegrations,
 		d.CustomIntegrationGuilds,
 		d.CustomIntegrationGuildCounts,
+		d.CustomIntegrationHistory,
 		d.CustomIntegrationHeaders,
 		d.CustomIntegrationPlaceholders,
 		d.CustomIntegrationSecrets,
 		d.CustomIntegrationSecretValues,
+		d.CustomIntegrationTags,
 		d.CustomColours,
 		d.DashboardUsers,
 		d.Embeds,
@@ -235,6 +305,7 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.FeedbackEnabled,
 		d.Forms,
 		d.FormInput,
+		d.FormInputConditions, // must be created after form_input table
 		d.GlobalBlacklist,
 		d.GuildLeaveTime,
 		d.GuildMetadata,
@@ -246,6 +317,9 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.OnCall,
 		d.Panel,
 		d.PanelAccessControlRules, // must be created after panels table
+		d.PanelAutoClose,          // must be created after panels table
+		d.SlaTargets,              // must be created after panels table
+		d.PanelCustomFields,       // must be created after panels table
 		d.MultiPanelTargets,       // must be created after panels table
 		d.PanelRoleMentions,
 		d.PanelUserMention,
@@ -261,11 +335,20 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.SupportTeam,
 		d.SupportTeamMembers,
 		d.SupportTeamRoles,
-		d.PanelTeams, // Must be created after panels & support teams tables
-		d.Tag,
+		d.PanelTeams,         // Must be created after panels & support teams tables
+		d.AssignmentPolicies, // Must be created after panels & support teams tables
+		d.OnCallSchedules,    // Must be created after support teams table
+		d.OnCallOverrides,    // Must be created after on-call schedules table
+		d.OnCallHolidays,     // Must be created after on-call schedules table
+		d.BusinessHours,      // Must be created after support teams table
+		d.TagCategories,      // Must be created before tags table
+		d.Tag,                // Must be created after tag categories table
+		d.TagAliases,         // Must be created after tags table
 		d.TicketLimit,
 		d.TicketPermissions,
 		d.Tickets,             // Must be created before members table
+		d.TicketIdCounters,    // Must be created after Tickets table
+		d.TicketLimitRules,    // Must be created after Tickets, panels & support teams tables
 		d.TicketLastMessage,   // Must be created after Tickets table
 		d.Participants,        // Must be created after Tickets table
 		d.AutoCloseExclude,    // Must be created after Tickets table
@@ -278,9 +361,22 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.FirstResponseTime,
 		d.TicketMembers,
 		d.TicketClaims,
+		d.TicketLabels,
+		d.TicketAssignments,       // Must be created after Tickets & TicketClaims tables
+		d.TicketLabelAssignments,  // Must be created after Tickets & TicketLabels tables
+		d.TicketCustomFieldValues, // Must be created after Tickets & PanelCustomFields tables
+		d.TicketFormResponses,     // Must be created after Tickets, forms & form_input tables
+		d.ScheduledTicketActions,  // Must be created after Tickets table
+		d.EscalationPolicies,      // Must be created after Tickets, panels & support teams tables
+		d.Macros,                  // Must be created after Tickets, TicketLabels & tags tables
+		d.TagUsages,               // Must be created after Tickets & tags tables
+		d.TicketNotes,             // Must be created after Tickets table
+		d.TicketLinks,             // Must be created after Tickets table
+		d.TicketReopens,           // Must be created after Tickets table
 		d.UsedKeys,
 		d.UsersCanClose,
 		d.UserGuilds,
+		d.ViewRefreshes,
 		d.VoteCredits,
 		d.Votes,
 		d.Webhooks,
@@ -291,6 +387,8 @@ func (d *Database) CreateTables(ctx context.Context, pool *pgxpool.Pool) {
 		d.WhitelabelStatuses,
 		d.WhitelabelUsers,
 	)
+
+	mustMigrate(ctx, pool, migrations...)
 }
 
 func (d *Database) Views() []View {
@@ -6,11 +6,10 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type DiscordEntitlements struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -27,7 +26,7 @@ var (
 	discordEntitlementsListAll string
 )
 
-func newDiscordEntitlementsTable(db *pgxpool.Pool) *DiscordEntitlements {
+func newDiscordEntitlementsTable(db *Pool) *DiscordEntitlements {
 	return &DiscordEntitlements{
 		db,
 	}
@@ -45,7 +44,7 @@ func (e *DiscordEntitlements) Create(ctx context.Context, tx pgx.Tx, discordId u
 func (e *DiscordEntitlements) GetEntitlementId(ctx context.Context, tx pgx.Tx, discordId uint64) (*uuid.UUID, error) {
 	var entitlementId uuid.UUID
 	if err := tx.QueryRow(ctx, discordEntitlementsGetEntitlementId, discordId).Scan(&entitlementId); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		} else {
 			return nil, err
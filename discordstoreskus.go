@@ -5,12 +5,10 @@ import (
 	_ "embed"
 	"errors"
 	"github.com/TicketsBot/common/model"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type DiscordStoreSkus struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -21,7 +19,7 @@ var (
 	discordStoreSkusGetSku string
 )
 
-func newDiscordStoreSkusTable(db *pgxpool.Pool) *DiscordStoreSkus {
+func newDiscordStoreSkusTable(db *Pool) *DiscordStoreSkus {
 	return &DiscordStoreSkus{
 		db,
 	}
@@ -34,7 +32,7 @@ func (DiscordStoreSkus) Schema() string {
 func (e *DiscordStoreSkus) GetSku(ctx context.Context, discordId uint64) (*model.Sku, error) {
 	var sku model.Sku
 	if err := e.QueryRow(ctx, discordStoreSkusGetSku, discordId).Scan(&sku.Id, &sku.Label, &sku.SkuType); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		}
 
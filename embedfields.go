@@ -1,9 +1,6 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type EmbedField struct {
 	FieldId int    `json:"field_id"`
@@ -14,10 +11,10 @@ type EmbedField struct {
 }
 
 type EmbedFieldsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newEmbedFieldsTable(db *pgxpool.Pool) *EmbedFieldsTable {
+func newEmbedFieldsTable(db *Pool) *EmbedFieldsTable {
 	return &EmbedFieldsTable{
 		db,
 	}
@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
@@ -30,10 +29,10 @@ type CustomEmbedWithFields struct {
 }
 
 type EmbedsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newEmbedsTable(db *pgxpool.Pool) *EmbedsTable {
+func newEmbedsTable(db *Pool) *EmbedsTable {
 	return &EmbedsTable{
 		db,
 	}
@@ -7,12 +7,11 @@ import (
 	"github.com/TicketsBot/common/model"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type Entitlements struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -47,7 +46,7 @@ var (
 	entitlementsIncreaseExpiry string
 )
 
-func newEntitlementsTable(db *pgxpool.Pool) *Entitlements {
+func newEntitlementsTable(db *Pool) *Entitlements {
 	return &Entitlements{
 		db,
 	}
@@ -117,7 +116,7 @@ func (e *Entitlements) GetById(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*m
 		&entitlement.Source,
 		&entitlement.ExpiresAt,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		}
 
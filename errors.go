@@ -0,0 +1,98 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// ErrNotFound is returned when a row that was looked up does not exist. For compatibility with existing callers,
+// errors.Is(ErrNotFound, pgx.ErrNoRows) also holds.
+var ErrNotFound error = notFoundError{}
+
+var (
+	// ErrConflict is returned when a write violates a unique or exclusion constraint
+	ErrConflict = errors.New("conflicts with an existing row")
+
+	// ErrForeignKey is returned when a write references a row that does not exist, or deletes a row that is still
+	// referenced
+	ErrForeignKey = errors.New("foreign key violation")
+
+	// ErrCheckViolation is returned when a write violates a check or not null constraint
+	ErrCheckViolation = errors.New("check constraint violation")
+)
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string {
+	return "not found"
+}
+
+func (notFoundError) Is(target error) bool {
+	return target == pgx.ErrNoRows
+}
+
+// ConstraintError is returned when a write violates a constraint. errors.Is(err, Kind) holds, so callers can match
+// against ErrConflict, ErrForeignKey and ErrCheckViolation, and use errors.As to retrieve the constraint name.
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+	Detail     string
+	pgErr      *pgconn.PgError
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return fmt.Sprintf("%s on %s: %s", e.Kind.Error(), e.Table, e.pgErr.Message)
+	}
+
+	return fmt.Sprintf("%s on %s (%s): %s", e.Kind.Error(), e.Table, e.Constraint, e.pgErr.Message)
+}
+
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the underlying *pgconn.PgError
+func (e *ConstraintError) Unwrap() error {
+	return e.pgErr
+}
+
+// translateError maps pgx and Postgres errors onto the error types defined above. Errors that have no equivalent are
+// returned unchanged.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case "23505", "23P01": // unique_violation, exclusion_violation
+		kind = ErrConflict
+	case "23503": // foreign_key_violation
+		kind = ErrForeignKey
+	case "23514", "23502": // check_violation, not_null_violation
+		kind = ErrCheckViolation
+	default:
+		return err
+	}
+
+	return &ConstraintError{
+		Kind:       kind,
+		Table:      pgErr.TableName,
+		Constraint: pgErr.ConstraintName,
+		Detail:     pgErr.Detail,
+		pgErr:      pgErr,
+	}
+}
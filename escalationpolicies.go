@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"time"
+)
+
+type EscalationAction string
+
+const (
+	// EscalationActionSupportTeam adds the support team in TeamId to the ticket
+	EscalationActionSupportTeam EscalationAction = "support_team"
+	// EscalationActionOnCallRole mentions the on-call role in RoleId
+	EscalationActionOnCallRole EscalationAction = "on_call_role"
+	// EscalationActionAdmins notifies the guild's admins
+	EscalationActionAdmins EscalationAction = "admins"
+)
+
+// EscalationPolicy escalates tickets opened from the panel that go unanswered by staff. Steps are run in order of
+// Position, each once the ticket has been waiting for a staff reply for DelayMinutes. Delays are measured from when
+// the ticket started waiting, not from the previous step, so they should increase with each step.
+type EscalationPolicy struct {
+	Id      int              `json:"id"`
+	GuildId uint64           `json:"guild_id,string"`
+	PanelId int              `json:"panel_id"`
+	Enabled bool             `json:"enabled"`
+	Steps   []EscalationStep `json:"steps"`
+}
+
+type EscalationStep struct {
+	Position     int              `json:"position"`
+	DelayMinutes int              `json:"delay_minutes"`
+	Action       EscalationAction `json:"action"`
+	TeamId       *int             `json:"team_id"`
+	RoleId       *uint64          `json:"role_id,string"`
+}
+
+// DueEscalation is the next step of a policy that should be run on a ticket. UnansweredSince is the time the ticket
+// started waiting for a staff reply, as recorded by TicketLastMessageTable.Set, or the time the ticket was opened if
+// there are no messages, and must be passed back to CompleteStep.
+type DueEscalation struct {
+	GuildId         uint64         `json:"guild_id,string"`
+	TicketId        int            `json:"ticket_id"`
+	ChannelId       *uint64        `json:"channel_id,string"`
+	PolicyId        int            `json:"policy_id"`
+	UnansweredSince time.Time      `json:"unanswered_since"`
+	Step            EscalationStep `json:"step"`
+}
+
+type EscalationPolicies struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/escalation_policies/schema.sql
+	escalationPoliciesSchema string
+
+	//go:embed sql/escalation_policies/get_due_steps.sql
+	escalationPoliciesGetDueSteps string
+
+	//go:embed sql/escalation_policies/complete_step.sql
+	escalationPoliciesCompleteStep string
+)
+
+func newEscalationPolicies(db *Pool) *EscalationPolicies {
+	return &EscalationPolicies{
+		db,
+	}
+}
+
+func (EscalationPolicies) Schema() string {
+	return escalationPoliciesSchema
+}
+
+// GetByPanel returns the panel's policy, including its steps
+func (p *EscalationPolicies) GetByPanel(ctx context.Context, panelId int) (EscalationPolicy, error) {
+	query := `SELECT "id", "guild_id", "panel_id", "enabled" FROM escalation_policies WHERE "panel_id" = $1;`
+
+	var policy EscalationPolicy
+	if err := p.QueryRow(ctx, query, panelId).Scan(&policy.Id, &policy.GuildId, &policy.PanelId, &policy.Enabled); err != nil {
+		return EscalationPolicy{}, err
+	}
+
+	stepsQuery := `
+SELECT "position", "delay_minutes", "action", "team_id", "role_id"
+FROM escalation_steps
+WHERE "policy_id" = $1
+ORDER BY "position";`
+
+	rows, err := p.Query(ctx, stepsQuery, policy.Id)
+	if err != nil {
+		return EscalationPolicy{}, err
+	}
+
+	defer rows.Close()
+
+	policy.Steps = make([]EscalationStep, 0)
+	for rows.Next() {
+		var step EscalationStep
+		if err := rows.Scan(&step.Position, &step.DelayMinutes, &step.Action, &step.TeamId, &step.RoleId); err != nil {
+			return EscalationPolicy{}, err
+		}
+
+		policy.Steps = append(policy.Steps, step)
+	}
+
+	return policy, rows.Err()
+}
+
+// Set creates or replaces the panel's policy and its steps. Steps are renumbered in the order given. Replacing the
+// steps restarts the escalation of tickets that are currently being escalated.
+func (p *EscalationPolicies) Set(ctx context.Context, policy EscalationPolicy) (id int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := p.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO escalation_policies("guild_id", "panel_id", "enabled")
+VALUES($1, $2, $3)
+ON CONFLICT("panel_id") DO UPDATE SET "enabled" = $3
+RETURNING "id";`
+
+	if err := tx.QueryRow(ctx, query, policy.GuildId, policy.PanelId, policy.Enabled).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM escalation_steps WHERE "policy_id" = $1;`, id); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ticket_escalation_state WHERE "policy_id" = $1;`, id); err != nil {
+		return 0, err
+	}
+
+	for position, step := range policy.Steps {
+		query := `
+INSERT INTO escalation_steps("policy_id", "position", "delay_minutes", "action", "team_id", "role_id")
+VALUES($1, $2, $3, $4, $5, $6);`
+
+		if _, err := tx.Exec(ctx, query, id, position, step.DelayMinutes, step.Action, step.TeamId, step.RoleId); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (p *EscalationPolicies) Delete(ctx context.Context, panelId int) (err error) {
+	_, err = p.Exec(ctx, `DELETE FROM escalation_policies WHERE "panel_id" = $1;`, panelId)
+	return
+}
+
+// GetDueSteps returns up to limit open tickets that are waiting for a staff reply and have an escalation step due,
+// most overdue first. Only the next step of each ticket is returned: once it has been run and passed to CompleteStep,
+// the following step becomes due.
+func (p *EscalationPolicies) GetDueSteps(ctx context.Context, limit int) ([]DueEscalation, error) {
+	rows, err := p.Query(ctx, escalationPoliciesGetDueSteps, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	escalations := make([]DueEscalation, 0)
+	for rows.Next() {
+		var escalation DueEscalation
+		if err := rows.Scan(
+			&escalation.GuildId,
+			&escalation.TicketId,
+			&escalation.ChannelId,
+			&escalation.PolicyId,
+			&escalation.UnansweredSince,
+			&escalation.Step.Position,
+			&escalation.Step.DelayMinutes,
+			&escalation.Step.Action,
+			&escalation.Step.TeamId,
+			&escalation.Step.RoleId,
+		); err != nil {
+			return nil, err
+		}
+
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, rows.Err()
+}
+
+// CompleteStep records that the step has been run on the ticket. It returns false if the step, or a later one, had
+// already been recorded, so that workers can claim a step by completing it before running it, and skip it otherwise.
+func (p *EscalationPolicies) CompleteStep(ctx context.Context, escalation DueEscalation) (bool, error) {
+	var applied bool
+	err := p.QueryRow(ctx, escalationPoliciesCompleteStep,
+		escalation.GuildId,
+		escalation.TicketId,
+		escalation.PolicyId,
+		escalation.UnansweredSince,
+		escalation.Step.Position,
+	).Scan(&applied)
+
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+
+	return applied, err
+}
+
+// Reset clears the escalation state of the ticket, so that the policy starts again from the first step. A staff reply
+// recorded by TicketLastMessageTable.Set already has this effect once the customer next messages.
+func (p *EscalationPolicies) Reset(ctx context.Context, guildId uint64, ticketId int) (err error) {
+	_, err = p.Exec(ctx, `DELETE FROM ticket_escalation_state WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId)
+	return
+}
@@ -3,7 +3,6 @@ package database
 import (
 	"context"
 	_ "embed"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type ExitSurveyResponse struct {
@@ -19,10 +18,10 @@ type QuestionResponse struct {
 }
 
 type ExitSurveyResponses struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newExitSurveyResponses(db *pgxpool.Pool) *ExitSurveyResponses {
+func newExitSurveyResponses(db *Pool) *ExitSurveyResponses {
 	return &ExitSurveyResponses{
 		db,
 	}
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type FeedbackEnabled struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newFeedbackEnabled(db *pgxpool.Pool) *FeedbackEnabled {
+func newFeedbackEnabled(db *Pool) *FeedbackEnabled {
 	return &FeedbackEnabled{
 		db,
 	}
@@ -21,7 +20,7 @@ func (FeedbackEnabled) Schema() string {
 }
 
 func (f *FeedbackEnabled) Get(ctx context.Context, guildId uint64) (feedbackEnabled bool, e error) {
-	if err := f.QueryRow(ctx, `SELECT "feedback_enabled" from feedback_enabled WHERE "guild_id" = $1;`, guildId).Scan(&feedbackEnabled); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, `SELECT "feedback_enabled" from feedback_enabled WHERE "guild_id" = $1;`, guildId).Scan(&feedbackEnabled); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -2,17 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type FirstResponseTime struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newFirstResponseTime(db *pgxpool.Pool) *FirstResponseTime {
+func newFirstResponseTime(db *Pool) *FirstResponseTime {
 	return &FirstResponseTime{
 		db,
 	}
@@ -34,7 +33,7 @@ CREATE INDEX IF NOT EXISTS first_response_time_guild_id ON first_response_time("
 
 func (f *FirstResponseTime) HasResponse(ctx context.Context, guildId uint64, ticketId int) (hasResponse bool, e error) {
 	query := `SELECT EXISTS(SELECT 1 FROM first_response_time WHERE "guild_id" = $1 AND "ticket_id" = $2);`
-	if err := f.QueryRow(ctx, query, guildId, ticketId).Scan(&hasResponse); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, query, guildId, ticketId).Scan(&hasResponse); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -55,7 +54,7 @@ ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticke
 WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id = $2;
 `
 
-	if err := f.QueryRow(ctx, query, parsedInterval, guildId).Scan(&responseTime); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, query, parsedInterval, guildId).Scan(&responseTime); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -64,7 +63,7 @@ WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id
 
 func (f *FirstResponseTime) GetAverageAllTime(ctx context.Context, guildId uint64) (responseTime *time.Duration, e error) {
 	query := `SELECT AVG(response_time) FROM first_response_time WHERE first_response_time.guild_id = $1;`
-	if err := f.QueryRow(ctx, query, guildId).Scan(&responseTime); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, query, guildId).Scan(&responseTime); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -84,7 +83,7 @@ INNER JOIN tickets
 ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
 WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id = $2 AND first_response_time.user_id = $3;`
 
-	if err := f.QueryRow(ctx, query, parsedInterval, guildId, userId).Scan(&responseTime); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, query, parsedInterval, guildId, userId).Scan(&responseTime); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -93,7 +92,85 @@ WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id
 
 func (f *FirstResponseTime) GetAverageAllTimeUser(ctx context.Context, guildId, userId uint64) (responseTime *time.Duration, e error) {
 	query := `SELECT AVG(response_time) FROM first_response_time WHERE first_response_time.guild_id = $1 AND first_response_time.user_id = $2;`
-	if err := f.QueryRow(ctx, query, guildId, userId).Scan(&responseTime); err != nil && err != pgx.ErrNoRows {
+	if err := f.QueryRow(ctx, query, guildId, userId).Scan(&responseTime); err != nil && !errors.Is(err, ErrNotFound) {
+		e = err
+	}
+
+	return
+}
+
+// GetAverageBusinessTime is GetAverage, counting only the time within the calendar's business hours
+func (f *FirstResponseTime) GetAverageBusinessTime(ctx context.Context, guildId uint64, calendar BusinessCalendar, interval time.Duration) (*time.Duration, error) {
+	query := `
+SELECT tickets.open_time, tickets.open_time + first_response_time.response_time
+FROM first_response_time
+INNER JOIN tickets
+ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
+WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id = $2;`
+
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.getAverageBusinessTime(ctx, calendar, query, parsedInterval, guildId)
+}
+
+// GetAverageUserBusinessTime is GetAverageUser, counting only the time within the calendar's business hours
+func (f *FirstResponseTime) GetAverageUserBusinessTime(ctx context.Context, guildId, userId uint64, calendar BusinessCalendar, interval time.Duration) (*time.Duration, error) {
+	query := `
+SELECT tickets.open_time, tickets.open_time + first_response_time.response_time
+FROM first_response_time
+INNER JOIN tickets
+ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
+WHERE tickets.open_time > NOW() - $1::interval AND first_response_time.guild_id = $2 AND first_response_time.user_id = $3;`
+
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.getAverageBusinessTime(ctx, calendar, query, parsedInterval, guildId, userId)
+}
+
+func (f *FirstResponseTime) getAverageBusinessTime(ctx context.Context, calendar BusinessCalendar, query string, args ...interface{}) (*time.Duration, error) {
+	return averageBusinessDuration(ctx, f.Pool, calendar, query, args...)
+}
+
+// slaBreachCondition matches tickets that were not responded to within their first response target. Tickets that
+// were closed without a response are counted as breaches if they were open for longer than the target.
+const slaBreachCondition = `target.effective_target IS NOT NULL AND
+	COALESCE(first_response_time.response_time, COALESCE(tickets.close_time, NOW()) - tickets.open_time) > target.effective_target`
+
+func (f *FirstResponseTime) GetSlaBreachCount(ctx context.Context, guildId uint64, interval time.Duration) (count int, e error) {
+	parsedInterval := pgtype.Interval{}
+	if err := parsedInterval.Set(interval); err != nil {
+		return 0, err
+	}
+
+	query := `
+SELECT COUNT(*)
+FROM tickets` + slaTargetJoin + `
+LEFT JOIN first_response_time
+ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
+WHERE tickets.open_time > NOW() - $1::interval AND tickets.guild_id = $2 AND ` + slaBreachCondition + `;`
+
+	if err := f.QueryRow(ctx, query, parsedInterval, guildId).Scan(&count); err != nil {
+		e = err
+	}
+
+	return
+}
+
+func (f *FirstResponseTime) GetSlaBreachCountAllTime(ctx context.Context, guildId uint64) (count int, e error) {
+	query := `
+SELECT COUNT(*)
+FROM tickets` + slaTargetJoin + `
+LEFT JOIN first_response_time
+ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
+WHERE tickets.guild_id = $1 AND ` + slaBreachCondition + `;`
+
+	if err := f.QueryRow(ctx, query, guildId).Scan(&count); err != nil {
 		e = err
 	}
 
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type FormInputConditionOperator string
+
+const (
+	FormInputConditionEquals    FormInputConditionOperator = "equals"
+	FormInputConditionNotEquals FormInputConditionOperator = "not_equals"
+	FormInputConditionContains  FormInputConditionOperator = "contains"
+)
+
+// FormInputCondition controls whether an input is shown, based on the answer given to an earlier input on the same
+// form. An input with conditions is only shown if all of its conditions are met. Comparisons are case-insensitive.
+type FormInputCondition struct {
+	Id          int                        `json:"id"`
+	FormInputId int                        `json:"form_input_id"`
+	DependsOnId int                        `json:"depends_on_id"`
+	Operator    FormInputConditionOperator `json:"operator"`
+	Value       string                     `json:"value"`
+}
+
+var ErrFormInputConditionCycle = errors.New("condition would create a dependency cycle")
+
+type FormInputConditions struct {
+	*Pool
+}
+
+func newFormInputConditions(db *Pool) *FormInputConditions {
+	return &FormInputConditions{
+		db,
+	}
+}
+
+func (FormInputConditions) Schema() string {
+	return `
+DO $$
+BEGIN
+	CREATE TYPE form_input_condition_operator AS ENUM ('equals', 'not_equals', 'contains');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS form_input_conditions(
+	"id" SERIAL NOT NULL UNIQUE,
+	"form_input_id" int NOT NULL,
+	"depends_on_id" int NOT NULL,
+	"operator" form_input_condition_operator NOT NULL,
+	"value" VARCHAR(255) NOT NULL,
+	FOREIGN KEY("form_input_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	FOREIGN KEY("depends_on_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	CONSTRAINT form_input_conditions_not_self CHECK ("form_input_id" != "depends_on_id"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS form_input_conditions_form_input_id ON form_input_conditions("form_input_id");
+CREATE INDEX IF NOT EXISTS form_input_conditions_depends_on_id ON form_input_conditions("depends_on_id");
+`
+}
+
+// GetByForm returns the conditions of every input on the form, keyed by the ID of the input they control
+func (c *FormInputConditions) GetByForm(ctx context.Context, formId int) (map[int][]FormInputCondition, error) {
+	query := `
+SELECT form_input_conditions."id", form_input_conditions."form_input_id", form_input_conditions."depends_on_id", form_input_conditions."operator", form_input_conditions."value"
+FROM form_input_conditions
+INNER JOIN form_input ON form_input_conditions.form_input_id = form_input.id
+WHERE form_input.form_id = $1
+ORDER BY form_input_conditions."id";`
+
+	rows, err := c.Query(ctx, query, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	conditions := make(map[int][]FormInputCondition)
+	for rows.Next() {
+		var condition FormInputCondition
+		if err := rows.Scan(&condition.Id, &condition.FormInputId, &condition.DependsOnId, &condition.Operator, &condition.Value); err != nil {
+			return nil, err
+		}
+
+		conditions[condition.FormInputId] = append(conditions[condition.FormInputId], condition)
+	}
+
+	return conditions, rows.Err()
+}
+
+// Create returns ErrNotFound if either input does not belong to the form, and ErrFormInputConditionCycle if the input
+// that the condition depends on already depends on the input, directly or through other conditions.
+func (c *FormInputConditions) Create(ctx context.Context, formId int, condition FormInputCondition) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := c.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Serialise condition changes on the form, so that two conditions created at once cannot form a cycle together
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM forms WHERE "form_id" = $1 FOR NO KEY UPDATE;`, formId); err != nil {
+		return 0, err
+	}
+
+	cycleQuery := `
+WITH RECURSIVE dependencies AS (
+	SELECT "depends_on_id" FROM form_input_conditions WHERE "form_input_id" = $2
+	UNION
+	SELECT form_input_conditions."depends_on_id"
+	FROM form_input_conditions
+	INNER JOIN dependencies ON form_input_conditions."form_input_id" = dependencies."depends_on_id"
+)
+SELECT EXISTS(SELECT 1 FROM dependencies WHERE "depends_on_id" = $1);`
+
+	var cycle bool
+	if err := tx.QueryRow(ctx, cycleQuery, condition.FormInputId, condition.DependsOnId).Scan(&cycle); err != nil {
+		return 0, err
+	}
+
+	if cycle {
+		return 0, ErrFormInputConditionCycle
+	}
+
+	query := `
+INSERT INTO form_input_conditions("form_input_id", "depends_on_id", "operator", "value")
+SELECT $2, $3, $4, $5
+WHERE (SELECT COUNT(*) FROM form_input WHERE "form_id" = $1 AND "id" IN ($2, $3)) = 2
+RETURNING "id";`
+
+	var id int
+	if err := tx.QueryRow(ctx, query, formId, condition.FormInputId, condition.DependsOnId, condition.Operator, condition.Value).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+func (c *FormInputConditions) Delete(ctx context.Context, formInputId, conditionId int) (err error) {
+	query := `DELETE FROM form_input_conditions WHERE "id" = $1 AND "form_input_id" = $2;`
+	_, err = c.Exec(ctx, query, conditionId, formInputId)
+	return
+}
+
+// DeleteAll removes every condition controlling the input, so that it is always shown
+func (c *FormInputConditions) DeleteAll(ctx context.Context, formInputId int) (err error) {
+	_, err = c.Exec(ctx, `DELETE FROM form_input_conditions WHERE "form_input_id" = $1;`, formInputId)
+	return
+}
+
+func (c FormInputCondition) matches(answer string) bool {
+	switch c.Operator {
+	case FormInputConditionEquals:
+		return strings.EqualFold(answer, c.Value)
+	case FormInputConditionNotEquals:
+		return !strings.EqualFold(answer, c.Value)
+	case FormInputConditionContains:
+		return strings.Contains(strings.ToLower(answer), strings.ToLower(c.Value))
+	default:
+		return false
+	}
+}
+
+type formInputState uint8
+
+const (
+	formInputPending formInputState = iota
+	formInputShown
+	formInputHidden
+)
+
+// NextFormInputs returns the inputs to present next, given the answers submitted so far, keyed by input ID. Inputs
+// that were submitted blank should be present in answers with an empty string. inputs must be ordered by page and
+// position, as returned by FormInputTable.GetInputs, and conditions keyed by input ID, as returned by
+// FormInputConditions.GetByForm.
+//
+// The returned inputs are the unanswered, visible inputs on the earliest page that has any. An input whose conditions
+// depend on an input that has not yet been answered is deferred to a later call, as a modal cannot change once shown.
+// Conditions on inputs that are themselves hidden are never met. Inputs whose conditions can never be evaluated, as
+// they depend on each other in a cycle, are shown once nothing else is left to ask. An empty slice means the form is
+// complete.
+func NextFormInputs(inputs []FormInput, conditions map[int][]FormInputCondition, answers map[int]string) []FormInput {
+	states := make(map[int]formInputState, len(inputs))
+	for _, input := range inputs {
+		states[input.Id] = formInputPending
+	}
+
+	// Conditions may depend on inputs later in the form, so repeat until no more inputs can be resolved
+	for changed := true; changed; {
+		changed = false
+		for _, input := range inputs {
+			if states[input.Id] != formInputPending {
+				continue
+			}
+
+			if state := resolveFormInputState(conditions[input.Id], answers, states); state != formInputPending {
+				states[input.Id] = state
+				changed = true
+			}
+		}
+	}
+
+	next := unansweredFormInputs(inputs, answers, states, formInputShown)
+	if len(next) == 0 {
+		// Every shown input has been answered, so any input still pending depends on a cycle of conditions that no
+		// answer can resolve. Conditions should prevent cycles from being created, but ask rather than skip them.
+		next = unansweredFormInputs(inputs, answers, states, formInputPending)
+	}
+
+	return next
+}
+
+// unansweredFormInputs returns the unanswered inputs in the given state, on the earliest page that has any
+func unansweredFormInputs(inputs []FormInput, answers map[int]string, states map[int]formInputState, state formInputState) []FormInput {
+	next := make([]FormInput, 0)
+	for _, input := range inputs {
+		if len(next) > 0 && input.Page != next[0].Page {
+			break
+		}
+
+		if _, answered := answers[input.Id]; answered || states[input.Id] != state {
+			continue
+		}
+
+		next = append(next, input)
+	}
+
+	return next
+}
+
+// resolveFormInputState returns hidden if any condition is not met, even if others cannot be evaluated yet
+func resolveFormInputState(conditions []FormInputCondition, answers map[int]string, states map[int]formInputState) formInputState {
+	state := formInputShown
+	for _, condition := range conditions {
+		answer, answered := answers[condition.DependsOnId]
+		if !answered {
+			dependencyState, ok := states[condition.DependsOnId]
+			if !ok || dependencyState == formInputHidden {
+				// Conditions on hidden inputs, or inputs that are not on the form, are never met
+				return formInputHidden
+			}
+
+			// The input has not been answered yet, so the condition cannot be evaluated
+			state = formInputPending
+		} else if !condition.matches(answer) {
+			return formInputHidden
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextFormInputs(t *testing.T) {
+	const (
+		category = iota + 1
+		invoiceNumber
+		refundReason
+		errorMessage
+		details
+		contactTime
+	)
+
+	// Billing questions are only asked when the category is Billing, and the refund reason only if an invoice number
+	// was given. Details, on the second page, controls whether a contact time is asked for on the first page.
+	supportForm := []FormInput{
+		{Id: category, Page: 1, Position: 1},
+		{Id: invoiceNumber, Page: 1, Position: 2},
+		{Id: refundReason, Page: 1, Position: 3},
+		{Id: errorMessage, Page: 1, Position: 4},
+		{Id: contactTime, Page: 1, Position: 5},
+		{Id: details, Page: 2, Position: 1},
+	}
+
+	supportConditions := map[int][]FormInputCondition{
+		invoiceNumber: {{FormInputId: invoiceNumber, DependsOnId: category, Operator: FormInputConditionEquals, Value: "Billing"}},
+		refundReason: {
+			{FormInputId: refundReason, DependsOnId: category, Operator: FormInputConditionEquals, Value: "Billing"},
+			{FormInputId: refundReason, DependsOnId: invoiceNumber, Operator: FormInputConditionNotEquals, Value: ""},
+		},
+		errorMessage: {{FormInputId: errorMessage, DependsOnId: category, Operator: FormInputConditionEquals, Value: "Technical"}},
+		contactTime:  {{FormInputId: contactTime, DependsOnId: details, Operator: FormInputConditionContains, Value: "call me"}},
+	}
+
+	const (
+		first = iota + 1
+		second
+		unconditional
+	)
+
+	cycleForm := []FormInput{
+		{Id: first, Page: 1, Position: 1},
+		{Id: second, Page: 1, Position: 2},
+		{Id: unconditional, Page: 1, Position: 3},
+	}
+
+	cycleConditions := map[int][]FormInputCondition{
+		first:  {{FormInputId: first, DependsOnId: second, Operator: FormInputConditionEquals, Value: "yes"}},
+		second: {{FormInputId: second, DependsOnId: first, Operator: FormInputConditionEquals, Value: "yes"}},
+	}
+
+	tests := []struct {
+		name       string
+		inputs     []FormInput
+		conditions map[int][]FormInputCondition
+		answers    map[int]string
+		expected   []int
+	}{
+		{
+			name:       "dependents wait for their dependency",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{},
+			expected:   []int{category},
+		},
+		{
+			name:       "billing branch",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "billing"},
+			expected:   []int{invoiceNumber},
+		},
+		{
+			name:       "billing branch continues",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Billing", invoiceNumber: "INV-1"},
+			expected:   []int{refundReason},
+		},
+		{
+			name:       "forward dependency across pages",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Billing", invoiceNumber: "INV-1", refundReason: "Duplicate charge"},
+			expected:   []int{details},
+		},
+		{
+			name:       "forward dependency answered",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Billing", invoiceNumber: "INV-1", refundReason: "Duplicate charge", details: "Please call me"},
+			expected:   []int{contactTime},
+		},
+		{
+			name:       "forward dependency not met",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Billing", invoiceNumber: "INV-1", refundReason: "Duplicate charge", details: "Email only"},
+			expected:   []int{},
+		},
+		{
+			name:       "hidden dependency",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Technical"},
+			expected:   []int{errorMessage},
+		},
+		{
+			name:       "blank answer hides dependents",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "Billing", invoiceNumber: ""},
+			expected:   []int{details},
+		},
+		{
+			name:       "blank answer matches no branch",
+			inputs:     supportForm,
+			conditions: supportConditions,
+			answers:    map[int]string{category: "", details: ""},
+			expected:   []int{},
+		},
+		{
+			name:       "cycle is deferred",
+			inputs:     cycleForm,
+			conditions: cycleConditions,
+			answers:    map[int]string{},
+			expected:   []int{unconditional},
+		},
+		{
+			name:       "cycle is shown",
+			inputs:     cycleForm,
+			conditions: cycleConditions,
+			answers:    map[int]string{unconditional: "a"},
+			expected:   []int{first, second},
+		},
+		{
+			name:       "cycle is complete",
+			inputs:     cycleForm,
+			conditions: cycleConditions,
+			answers:    map[int]string{unconditional: "a", first: "no", second: "no"},
+			expected:   []int{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := NextFormInputs(test.inputs, test.conditions, test.answers)
+
+			ids := make([]int, len(next))
+			for i, input := range next {
+				ids[i] = input.Id
+			}
+
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("expected inputs %v, got %v", test.expected, ids)
+			}
+		})
+	}
+}
@@ -5,27 +5,245 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
+type FormInputType string
+
+const (
+	FormInputTypeShortText     FormInputType = "short_text"
+	FormInputTypeParagraph     FormInputType = "paragraph"
+	FormInputTypeSelect        FormInputType = "select"
+	FormInputTypeUserSelect    FormInputType = "user_select"
+	FormInputTypeRoleSelect    FormInputType = "role_select"
+	FormInputTypeChannelSelect FormInputType = "channel_select"
+	FormInputTypeNumber        FormInputType = "number"
+)
+
+// Discord text input styles, stored in the style column
+const (
+	formInputStyleShort     uint8 = 1
+	formInputStyleParagraph uint8 = 2
+)
+
+// MaxInputsPerPage is the number of inputs Discord permits in a single modal
+const MaxInputsPerPage = 5
+
+// FormInput is a single question on a form. Forms with more than MaxInputsPerPage inputs are split across several
+// pages, each shown as a separate modal: Position is the position of the input within its Page.
+//
+// Style is retained for text inputs, and is kept in sync with Type. MinLength, MaxLength and Pattern validate text
+// inputs, MinValue and MaxValue validate number inputs, and MinValues and MaxValues limit the number of selections
+// that may be made in select menus.
 type FormInput struct {
-	Id          int     `json:"id"`
-	FormId      int     `json:"form_id"`
-	Position    int     `json:"position"`
-	CustomId    string  `json:"custom_id"`
-	Style       uint8   `json:"style"`
+	Id          int               `json:"id"`
+	FormId      int               `json:"form_id"`
+	Page        int               `json:"page"`
+	Position    int               `json:"position"`
+	CustomId    string            `json:"custom_id"`
+	Type        FormInputType     `json:"type"`
+	Style       uint8             `json:"style"`
+	Label       string            `json:"label"`
+	Placeholder *string           `json:"placeholder,omitempty"`
+	Required    bool              `json:"required"`
+	MinLength   *uint16           `json:"min_length,omitempty"`
+	MaxLength   *uint16           `json:"max_length,omitempty"`
+	Pattern     *string           `json:"pattern,omitempty"`
+	MinValue    *float64          `json:"min_value,omitempty"`
+	MaxValue    *float64          `json:"max_value,omitempty"`
+	MinValues   *uint8            `json:"min_values,omitempty"`
+	MaxValues   *uint8            `json:"max_values,omitempty"`
+	Options     []FormInputOption `json:"options,omitempty"`
+}
+
+// FormInputOption is a choice in a select menu input
+type FormInputOption struct {
 	Label       string  `json:"label"`
-	Placeholder *string `json:"placeholder,omitempty"`
-	Required    bool    `json:"required"`
-	MinLength   *uint16 `json:"min_length,omitempty"`
-	MaxLength   *uint16 `json:"max_length,omitempty"`
+	Value       string  `json:"value"`
+	Description *string `json:"description,omitempty"`
+}
+
+type FormPage struct {
+	Number int         `json:"number"`
+	Inputs []FormInput `json:"inputs"`
+}
+
+var (
+	ErrInvalidFormAnswer = errors.New("invalid form answer")
+	ErrInvalidFormInput  = errors.New("invalid form input")
+)
+
+// formInputPatterns caches compiled patterns by their source, so that they are not recompiled for every answer
+var formInputPatterns sync.Map
+
+// compileFormInputPattern compiles a Pattern so that it must match the whole answer
+func compileFormInputPattern(pattern string) (*regexp.Regexp, error) {
+	if compiled, ok := formInputPatterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, err
+	}
+
+	formInputPatterns.Store(pattern, compiled)
+	return compiled, nil
+}
+
+// Validate returns an error wrapping ErrInvalidFormAnswer if the answer does not satisfy the input's constraints. Blank
+// answers are only rejected if the input is required. Pattern must match the whole answer, and number inputs must
+// parse as a number between MinValue and MaxValue. An error not wrapping ErrInvalidFormAnswer is returned if Pattern
+// is not a valid regular expression, which can only happen for inputs that were stored before patterns were checked.
+func (i FormInput) Validate(answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		if i.Required {
+			return fmt.Errorf("%w: %s is required", ErrInvalidFormAnswer, i.Label)
+		}
+
+		return nil
+	}
+
+	switch i.resolvedType() {
+	case FormInputTypeShortText, FormInputTypeParagraph:
+		length := utf8.RuneCountInString(answer)
+		if i.MinLength != nil && length < int(*i.MinLength) {
+			return fmt.Errorf("%w: %s must be at least %d characters", ErrInvalidFormAnswer, i.Label, *i.MinLength)
+		}
+
+		if i.MaxLength != nil && length > int(*i.MaxLength) {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidFormAnswer, i.Label, *i.MaxLength)
+		}
+
+		if i.Pattern != nil {
+			pattern, err := compileFormInputPattern(*i.Pattern)
+			if err != nil {
+				return err
+			}
+
+			if !pattern.MatchString(answer) {
+				return fmt.Errorf("%w: %s does not match the required format", ErrInvalidFormAnswer, i.Label)
+			}
+		}
+	case FormInputTypeNumber:
+		value, err := strconv.ParseFloat(strings.TrimSpace(answer), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return fmt.Errorf("%w: %s must be a number", ErrInvalidFormAnswer, i.Label)
+		}
+
+		if i.MinValue != nil && value < *i.MinValue {
+			return fmt.Errorf("%w: %s must be at least %g", ErrInvalidFormAnswer, i.Label, *i.MinValue)
+		}
+
+		if i.MaxValue != nil && value > *i.MaxValue {
+			return fmt.Errorf("%w: %s must be at most %g", ErrInvalidFormAnswer, i.Label, *i.MaxValue)
+		}
+	}
+
+	return nil
+}
+
+// validateDefinition returns an error wrapping ErrInvalidFormInput if Pattern is not a valid regular expression, or
+// if the input is a select menu without any options. hasOptions reports whether the input already has options stored,
+// for updates which do not replace them.
+func (i FormInput) validateDefinition(hasOptions bool) error {
+	if i.Pattern != nil {
+		if _, err := compileFormInputPattern(*i.Pattern); err != nil {
+			return fmt.Errorf("%w: invalid pattern: %s", ErrInvalidFormInput, err.Error())
+		}
+	}
+
+	if i.resolvedType() == FormInputTypeSelect && len(i.Options) == 0 && !hasOptions {
+		return fmt.Errorf("%w: select inputs must have at least one option", ErrInvalidFormInput)
+	}
+
+	return nil
+}
+
+// validateUpdateTx validates the input, taking into account the options already stored if they are not being replaced
+func (f *FormInputTable) validateUpdateTx(ctx context.Context, tx pgx.Tx, input FormInput) error {
+	var hasOptions bool
+	if input.Options == nil && input.resolvedType() == FormInputTypeSelect {
+		query := `SELECT EXISTS(SELECT 1 FROM form_input_options WHERE "form_input_id" = $1);`
+		if err := tx.QueryRow(ctx, query, input.Id).Scan(&hasOptions); err != nil {
+			return err
+		}
+	}
+
+	return input.validateDefinition(hasOptions)
+}
+
+// resolvedType returns the type of the input, deriving it from the style for inputs created before types existed
+func (i FormInput) resolvedType() FormInputType {
+	if i.Type != "" {
+		return i.Type
+	}
+
+	return formInputTypeFromStyle(i.Style)
+}
+
+// resolvedStyle returns the Discord text input style matching the input's type
+func (i FormInput) resolvedStyle() uint8 {
+	switch i.resolvedType() {
+	case FormInputTypeParagraph:
+		return formInputStyleParagraph
+	case FormInputTypeShortText, FormInputTypeNumber:
+		return formInputStyleShort
+	default:
+		return i.Style
+	}
+}
+
+func (i FormInput) resolvedPage() int {
+	if i.Page < 1 {
+		return 1
+	}
+
+	return i.Page
+}
+
+func formInputTypeFromStyle(style uint8) FormInputType {
+	if style == formInputStyleParagraph {
+		return FormInputTypeParagraph
+	}
+
+	return FormInputTypeShortText
+}
+
+const formInputColumns = `form_input.id, form_input.form_id, form_input.page, form_input.position, form_input.custom_id, form_input.type, form_input.style, form_input.label, form_input.placeholder, form_input.required, form_input.min_length, form_input.max_length, form_input.pattern, form_input.min_value, form_input.max_value, form_input.min_values, form_input.max_values`
+
+func (i *FormInput) fieldPtrs() []interface{} {
+	return []interface{}{
+		&i.Id,
+		&i.FormId,
+		&i.Page,
+		&i.Position,
+		&i.CustomId,
+		&i.Type,
+		&i.Style,
+		&i.Label,
+		&i.Placeholder,
+		&i.Required,
+		&i.MinLength,
+		&i.MaxLength,
+		&i.Pattern,
+		&i.MinValue,
+		&i.MaxValue,
+		&i.MinValues,
+		&i.MaxValues,
+	}
 }
 
 type FormInputTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newFormInputTable(db *pgxpool.Pool) *FormInputTable {
+func newFormInputTable(db *Pool) *FormInputTable {
 	return &FormInputTable{
 		db,
 	}
@@ -51,79 +269,144 @@ func (f FormInputTable) Schema() string {
 	PRIMARY KEY("id")
 	);
 	CREATE INDEX IF NOT EXISTS form_input_form_id ON form_input("form_id");
+
+	DO $$
+	BEGIN
+		CREATE TYPE form_input_type AS ENUM ('short_text', 'paragraph', 'select', 'user_select', 'role_select', 'channel_select', 'number');
+	EXCEPTION
+		WHEN duplicate_object THEN NULL;
+	END $$;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "type" form_input_type DEFAULT NULL;
+	UPDATE form_input SET "type" = CASE WHEN "style" = 2 THEN 'paragraph'::form_input_type ELSE 'short_text'::form_input_type END WHERE "type" IS NULL;
+	ALTER TABLE form_input ALTER COLUMN "type" SET NOT NULL;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "page" int NOT NULL DEFAULT 1 CHECK(page >= 1);
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "pattern" VARCHAR(255) DEFAULT NULL;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "min_value" double precision DEFAULT NULL;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "max_value" double precision DEFAULT NULL;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "min_values" int2 DEFAULT NULL;
+	ALTER TABLE form_input ADD COLUMN IF NOT EXISTS "max_values" int2 DEFAULT NULL;
+	DO $$
+	BEGIN
+		IF NOT EXISTS(SELECT 1 FROM pg_constraint WHERE conname = 'form_input_form_id_page_position_key') THEN
+			ALTER TABLE form_input DROP CONSTRAINT IF EXISTS form_input_form_id_position_key;
+			ALTER TABLE form_input ADD CONSTRAINT form_input_form_id_page_position_key UNIQUE("form_id", "page", "position") DEFERRABLE INITIALLY DEFERRED;
+		END IF;
+	END $$;
+
+	CREATE TABLE IF NOT EXISTS form_input_options(
+	"form_input_id" int NOT NULL,
+	"position" int2 NOT NULL,
+	"label" VARCHAR(100) NOT NULL,
+	"value" VARCHAR(100) NOT NULL,
+	"description" VARCHAR(100) DEFAULT NULL,
+	FOREIGN KEY("form_input_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	UNIQUE("form_input_id", "value"),
+	PRIMARY KEY("form_input_id", "position")
+	);
 	`
 }
 
 func (f *FormInputTable) Get(ctx context.Context, id int) (input FormInput, ok bool, e error) {
-	query := `SELECT "id", "form_id", "position", "custom_id", "style", "label", "placeholder", "required", "min_length", "max_length" FROM form_input WHERE "id" = $1; `
-
-	err := f.QueryRow(ctx, query, id).Scan(
-		&input.Id,
-		&input.FormId,
-		&input.Position,
-		&input.CustomId,
-		&input.Style,
-		&input.Label,
-		&input.Placeholder,
-		&input.Required,
-		&input.MinLength,
-		&input.MaxLength,
-	)
+	query := fmt.Sprintf(`SELECT %s FROM form_input WHERE "id" = $1;`, formInputColumns)
 
+	err := f.QueryRow(ctx, query, id).Scan(input.fieldPtrs()...)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return FormInput{}, false, nil
 		} else {
 			return FormInput{}, false, err
 		}
 	}
 
+	options, err := f.getOptions(ctx, `WHERE form_input_options.form_input_id = $1`, id)
+	if err != nil {
+		return FormInput{}, false, err
+	}
+
+	input.Options = options[input.Id]
 	return input, true, nil
 }
 
+// GetInputs returns the inputs of the form, ordered by page and then position
 func (f *FormInputTable) GetInputs(ctx context.Context, formId int) (inputs []FormInput, e error) {
-	query := `
-	SELECT "id", "form_id", "position", "custom_id", "style", "label", "placeholder", "required", "min_length", "max_length"
+	query := fmt.Sprintf(`
+	SELECT %s
 	FROM form_input
 	WHERE "form_id" = $1
-	ORDER BY "position" ASC; `
+	ORDER BY "page" ASC, "position" ASC; `, formInputColumns)
 
 	rows, err := f.Query(ctx, query, formId)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var input FormInput
-		if err := rows.Scan(&input.Id, &input.FormId, &input.Position, &input.CustomId, &input.Style, &input.Label, &input.Placeholder, &input.Required, &input.MinLength, &input.MaxLength); err != nil {
+		if err := rows.Scan(input.fieldPtrs()...); err != nil {
 			return nil, err
 		}
 
 		inputs = append(inputs, input)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	options, err := f.getOptions(ctx, `INNER JOIN form_input ON form_input_options.form_input_id = form_input.id WHERE form_input.form_id = $1`, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range inputs {
+		inputs[i].Options = options[inputs[i].Id]
+	}
+
 	return
 }
 
+// GetPages returns the inputs of the form grouped into pages, each of which is shown as a separate modal
+func (f *FormInputTable) GetPages(ctx context.Context, formId int) ([]FormPage, error) {
+	inputs, err := f.GetInputs(ctx, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]FormPage, 0)
+	for _, input := range inputs {
+		if len(pages) == 0 || pages[len(pages)-1].Number != input.Page {
+			pages = append(pages, FormPage{Number: input.Page})
+		}
+
+		pages[len(pages)-1].Inputs = append(pages[len(pages)-1].Inputs, input)
+	}
+
+	return pages, nil
+}
+
 // Form ID -> Form Input
 func (f *FormInputTable) GetInputsForGuild(ctx context.Context, guildId uint64) (inputs map[int][]FormInput, e error) {
-	query := `
-	SELECT form_input.id, form_input.form_id, form_input.position, form_input.custom_id, form_input.style, form_input.label, form_input.placeholder, form_input.required, form_input.min_length, form_input.max_length
+	query := fmt.Sprintf(`
+	SELECT %s
 	FROM form_input
 	INNER JOIN forms ON form_input.form_id = forms.form_id
 	WHERE forms.guild_id = $1
-	ORDER BY form_input.form_id, form_input.position ASC;
-	`
+	ORDER BY form_input.form_id, form_input.page, form_input.position ASC;
+	`, formInputColumns)
 
 	rows, err := f.Query(ctx, query, guildId)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	inputs = make(map[int][]FormInput)
 	for rows.Next() {
 		var input FormInput
-		if err := rows.Scan(&input.Id, &input.FormId, &input.Position, &input.CustomId, &input.Style, &input.Label, &input.Placeholder, &input.Required, &input.MinLength, &input.MaxLength); err != nil {
+		if err := rows.Scan(input.fieldPtrs()...); err != nil {
 			return nil, err
 		}
 
@@ -134,37 +417,105 @@ func (f *FormInputTable) GetInputsForGuild(ctx context.Context, guildId uint64)
 		inputs[input.FormId] = append(inputs[input.FormId], input)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	options, err := f.getGuildOptions(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, formInputs := range inputs {
+		for i := range formInputs {
+			formInputs[i].Options = options[formInputs[i].Id]
+		}
+	}
+
 	return
 }
 
 // custom_id -> FormInput
 func (f *FormInputTable) GetAllInputsByCustomId(ctx context.Context, guildId uint64) (map[string]FormInput, error) {
-	query := `
-	SELECT form_input.id, form_input.form_id, form_input.position, form_input.custom_id, form_input.style, form_input.label, form_input.placeholder, form_input.required, form_input.min_length, form_input.max_length
+	query := fmt.Sprintf(`
+	SELECT %s
 	FROM form_input
 	INNER JOIN forms ON form_input.form_id = forms.form_id
 	WHERE forms.guild_id = $1
-	ORDER BY form_input.position ASC;
-	`
+	ORDER BY form_input.page, form_input.position ASC;
+	`, formInputColumns)
 
 	rows, err := f.Query(ctx, query, guildId)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	inputs := make(map[string]FormInput)
 	for rows.Next() {
 		var input FormInput
-		if err := rows.Scan(&input.Id, &input.FormId, &input.Position, &input.CustomId, &input.Style, &input.Label, &input.Placeholder, &input.Required, &input.MinLength, &input.MaxLength); err != nil {
+		if err := rows.Scan(input.fieldPtrs()...); err != nil {
 			return nil, err
 		}
 
 		inputs[input.CustomId] = input
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	options, err := f.getGuildOptions(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	for customId, input := range inputs {
+		input.Options = options[input.Id]
+		inputs[customId] = input
+	}
+
 	return inputs, nil
 }
 
+func (f *FormInputTable) getGuildOptions(ctx context.Context, guildId uint64) (map[int][]FormInputOption, error) {
+	return f.getOptions(ctx, `
+	INNER JOIN form_input ON form_input_options.form_input_id = form_input.id
+	INNER JOIN forms ON form_input.form_id = forms.form_id
+	WHERE forms.guild_id = $1`, guildId)
+}
+
+// getOptions returns the select menu options of the inputs matched by the clause, keyed by input ID
+func (f *FormInputTable) getOptions(ctx context.Context, clause string, args ...interface{}) (map[int][]FormInputOption, error) {
+	query := fmt.Sprintf(`
+	SELECT form_input_options.form_input_id, form_input_options.label, form_input_options.value, form_input_options.description
+	FROM form_input_options
+	%s
+	ORDER BY form_input_options.form_input_id, form_input_options.position ASC;
+	`, clause)
+
+	rows, err := f.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	options := make(map[int][]FormInputOption)
+	for rows.Next() {
+		var inputId int
+		var option FormInputOption
+		if err := rows.Scan(&inputId, &option.Label, &option.Value, &option.Description); err != nil {
+			return nil, err
+		}
+
+		options[inputId] = append(options[inputId], option)
+	}
+
+	return options, rows.Err()
+}
+
 func (f *FormInputTable) Create(ctx context.Context,
 	formId int,
 	customId string,
@@ -176,13 +527,13 @@ func (f *FormInputTable) Create(ctx context.Context,
 	maxLength *uint16,
 ) (int, error) {
 	query := `
-	INSERT INTO form_input("form_id", "position", "custom_id", "style", "label", "placeholder", "required", "min_length", "max_length")
-	VALUES($1, (SELECT COALESCE(MAX("position"), 0) + 1 FROM form_input WHERE "form_id" = $1), $2, $3, $4, $5, $6, $7, $8)
+	INSERT INTO form_input("form_id", "position", "custom_id", "type", "style", "label", "placeholder", "required", "min_length", "max_length")
+	VALUES($1, (SELECT COALESCE(MAX("position"), 0) + 1 FROM form_input WHERE "form_id" = $1 AND "page" = 1), $2, $3, $4, $5, $6, $7, $8, $9)
 	RETURNING "id";
 	`
 
 	var id int
-	if err := f.QueryRow(ctx, query, formId, customId, style, label, placeholder, required, minLength, maxLength).Scan(&id); err != nil {
+	if err := f.QueryRow(ctx, query, formId, customId, formInputTypeFromStyle(style), style, label, placeholder, required, minLength, maxLength).Scan(&id); err != nil {
 		return 0, err
 	}
 
@@ -202,51 +553,208 @@ func (f *FormInputTable) CreateTx(
 	minLength *uint16,
 	maxLength *uint16,
 ) (int, error) {
+	return f.CreateInputTx(ctx, tx, FormInput{
+		FormId:      formId,
+		Page:        1,
+		Position:    position,
+		CustomId:    customId,
+		Style:       style,
+		Label:       label,
+		Placeholder: placeholder,
+		Required:    required,
+		MinLength:   minLength,
+		MaxLength:   maxLength,
+	})
+}
+
+func (f *FormInputTable) CreateInput(ctx context.Context, input FormInput) (int, error) {
+	tx, err := f.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	id, err := f.CreateInputTx(ctx, tx, input)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateInputTx creates the input, along with its select menu options. If Type is not set, it is derived from Style.
+// An error wrapping ErrInvalidFormInput is returned if the input's Pattern or options are invalid.
+func (f *FormInputTable) CreateInputTx(ctx context.Context, tx pgx.Tx, input FormInput) (int, error) {
+	if err := input.validateDefinition(false); err != nil {
+		return 0, err
+	}
+
 	query := `
-	INSERT INTO form_input("form_id", "position", "custom_id", "style", "label", "placeholder", "required", "min_length", "max_length")
-	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	INSERT INTO form_input("form_id", "page", "position", "custom_id", "type", "style", "label", "placeholder", "required", "min_length", "max_length", "pattern", "min_value", "max_value", "min_values", "max_values")
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	RETURNING "id";
 	`
 
 	var id int
-	if err := tx.QueryRow(ctx, query, formId, position, customId, style, label, placeholder, required, minLength, maxLength).Scan(&id); err != nil {
+	if err := tx.QueryRow(ctx, query,
+		input.FormId,
+		input.resolvedPage(),
+		input.Position,
+		input.CustomId,
+		input.resolvedType(),
+		input.resolvedStyle(),
+		input.Label,
+		input.Placeholder,
+		input.Required,
+		input.MinLength,
+		input.MaxLength,
+		input.Pattern,
+		input.MinValue,
+		input.MaxValue,
+		input.MinValues,
+		input.MaxValues,
+	).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := f.setOptionsTx(ctx, tx, id, input.Options); err != nil {
 		return 0, err
 	}
 
 	return id, nil
 }
 
-func (f *FormInputTable) Update(ctx context.Context, input FormInput) (err error) {
+// Update does not change the position of the input. Options are replaced, unless they are nil.
+func (f *FormInputTable) Update(ctx context.Context, input FormInput) error {
+	tx, err := f.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := f.validateUpdateTx(ctx, tx, input); err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE form_input
-	SET "style" = $2,
-	"label" = $3,
-	"placeholder" = $4,
-	"required" = $5,
-	"min_length" = $6,
-	"max_length" = $7
+	SET "type" = $2,
+	"style" = $3,
+	"label" = $4,
+	"placeholder" = $5,
+	"required" = $6,
+	"min_length" = $7,
+	"max_length" = $8,
+	"pattern" = $9,
+	"min_value" = $10,
+	"max_value" = $11,
+	"min_values" = $12,
+	"max_values" = $13
 	WHERE "id" = $1;
 	`
 
-	_, err = f.Exec(ctx, query, input.Id, input.Style, input.Label, input.Placeholder, input.Required, input.MinLength, input.MaxLength)
-	return
+	if _, err := tx.Exec(ctx, query,
+		input.Id,
+		input.resolvedType(),
+		input.resolvedStyle(),
+		input.Label,
+		input.Placeholder,
+		input.Required,
+		input.MinLength,
+		input.MaxLength,
+		input.Pattern,
+		input.MinValue,
+		input.MaxValue,
+		input.MinValues,
+		input.MaxValues,
+	); err != nil {
+		return err
+	}
+
+	if input.Options != nil {
+		if err := f.setOptionsTx(ctx, tx, input.Id, input.Options); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
+// UpdateTx also moves the input to its Page and Position. Options are replaced, unless they are nil.
 func (f *FormInputTable) UpdateTx(ctx context.Context, tx pgx.Tx, input FormInput) (err error) {
+	if err := f.validateUpdateTx(ctx, tx, input); err != nil {
+		return err
+	}
+
 	query := `
 	UPDATE form_input
-	SET "position" = $2,
-	"style" = $3,
-	"label"= $4,
-	"placeholder" = $5,
-	"required" = $6,
-	"min_length" = $7,
-	"max_length" = $8
+	SET "page" = $2,
+	"position" = $3,
+	"type" = $4,
+	"style" = $5,
+	"label"= $6,
+	"placeholder" = $7,
+	"required" = $8,
+	"min_length" = $9,
+	"max_length" = $10,
+	"pattern" = $11,
+	"min_value" = $12,
+	"max_value" = $13,
+	"min_values" = $14,
+	"max_values" = $15
 	WHERE "id" = $1;
 	`
 
-	_, err = tx.Exec(ctx, query, input.Id, input.Position, input.Style, input.Label, input.Placeholder, input.Required, input.MinLength, input.MaxLength)
-	return
+	if _, err := tx.Exec(ctx, query,
+		input.Id,
+		input.resolvedPage(),
+		input.Position,
+		input.resolvedType(),
+		input.resolvedStyle(),
+		input.Label,
+		input.Placeholder,
+		input.Required,
+		input.MinLength,
+		input.MaxLength,
+		input.Pattern,
+		input.MinValue,
+		input.MaxValue,
+		input.MinValues,
+		input.MaxValues,
+	); err != nil {
+		return err
+	}
+
+	if input.Options != nil {
+		return f.setOptionsTx(ctx, tx, input.Id, input.Options)
+	}
+
+	return nil
+}
+
+func (f *FormInputTable) setOptionsTx(ctx context.Context, tx pgx.Tx, inputId int, options []FormInputOption) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM form_input_options WHERE "form_input_id" = $1;`, inputId); err != nil {
+		return err
+	}
+
+	query := `
+	INSERT INTO form_input_options("form_input_id", "position", "label", "value", "description")
+	VALUES($1, $2, $3, $4, $5);
+	`
+
+	for position, option := range options {
+		if _, err := tx.Exec(ctx, query, inputId, position, option.Label, option.Value, option.Description); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // TODO: Remove this function. It is unused.
@@ -306,33 +814,24 @@ func (f *FormInputTable) SwapDirection(ctx context.Context, inputId, formId int,
 }
 
 func (f *FormInputTable) Delete(ctx context.Context, formInputId, formId int) (err error) {
-	query := `
-	WITH deleted_position AS (
-	DELETE FROM form_input
-	WHERE "id" = $1 AND "form_id" = $2
-	RETURNING "position"
-	)
-	UPDATE form_input
-	SET position = position-1
-	WHERE form_id = $2 AND position>(SELECT position FROM deleted_position);
-	`
-
-	_, err = f.Exec(ctx, query, formInputId, formId)
+	_, err = f.Exec(ctx, formInputDelete, formInputId, formId)
 	return
 }
 
 func (f *FormInputTable) DeleteTx(ctx context.Context, tx pgx.Tx, formInputId, formId int) (err error) {
-	query := `
-	WITH deleted_position AS (
+	_, err = tx.Exec(ctx, formInputDelete, formInputId, formId)
+	return
+}
+
+// formInputDelete deletes the input, and moves the inputs after it on the same page up by one position
+const formInputDelete = `
+	WITH deleted AS (
 	DELETE FROM form_input
 	WHERE "id" = $1 AND "form_id" = $2
-	RETURNING "position"
+	RETURNING "page", "position"
 	)
 	UPDATE form_input
 	SET position = position-1
-	WHERE form_id = $2 AND position>(SELECT position FROM deleted_position)
+	FROM deleted
+	WHERE form_input.form_id = $2 AND form_input.page = deleted.page AND form_input.position > deleted.position;
 `
-
-	_, err = tx.Exec(ctx, query, formInputId, formId)
-	return
-}
@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type Form struct {
@@ -14,10 +13,10 @@ type Form struct {
 }
 
 type FormsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newFormsTable(db *pgxpool.Pool) *FormsTable {
+func newFormsTable(db *Pool) *FormsTable {
 	return &FormsTable{
 		db,
 	}
@@ -41,7 +40,7 @@ func (f *FormsTable) Get(ctx context.Context, formId int) (form Form, ok bool, e
 
 	err := f.QueryRow(ctx, query, formId).Scan(&form.Id, &form.GuildId, &form.Title, &form.CustomId)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return Form{}, false, nil
 		} else {
 			return Form{}, false, err
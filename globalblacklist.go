@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type GlobalBlacklist struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newGlobalBlacklist(db *pgxpool.Pool) *GlobalBlacklist {
+func newGlobalBlacklist(db *Pool) *GlobalBlacklist {
 	return &GlobalBlacklist{
 		db,
 	}
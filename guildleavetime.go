@@ -3,15 +3,14 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type GuildLeaveTime struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newGuildLeaveTime(db *pgxpool.Pool) *GuildLeaveTime {
+func newGuildLeaveTime(db *Pool) *GuildLeaveTime {
 	return &GuildLeaveTime{
 		db,
 	}
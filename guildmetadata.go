@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type GuildMetadata struct {
@@ -17,10 +16,10 @@ func defaultGuildMetadata() GuildMetadata {
 }
 
 type GuildMetadataTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newGuildMetadataTable(db *pgxpool.Pool) *GuildMetadataTable {
+func newGuildMetadataTable(db *Pool) *GuildMetadataTable {
 	return &GuildMetadataTable{
 		db,
 	}
@@ -49,7 +48,7 @@ WHERE "guild_id" = $1;
 
 	if err == nil {
 		return metadata, nil
-	} else if err == pgx.ErrNoRows {
+	} else if errors.Is(err, ErrNotFound) {
 		return defaultGuildMetadata(), nil
 	} else {
 		return GuildMetadata{}, err
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+type QueryKind string
+
+const (
+	QueryKindExec        QueryKind = "exec"
+	QueryKindQuery       QueryKind = "query"
+	QueryKindQueryRow    QueryKind = "query_row"
+	QueryKindBatch       QueryKind = "batch"
+	QueryKindTransaction QueryKind = "transaction"
+)
+
+// QueryInfo describes a query, batch or transaction as it starts.
+type QueryInfo struct {
+	Kind QueryKind
+	// Table is the receiver type of the method that issued the query, e.g. "TicketTable". It is empty if the query was
+	// issued from outside this package.
+	Table string
+	// Method is the name of the method that issued the query, e.g. "GetByChannel"
+	Method string
+	// SQL is empty for batches and transactions
+	SQL string
+	// InTransaction is true if the query was executed as part of a transaction
+	InTransaction bool
+}
+
+// QueryResult describes the outcome of a query, batch or transaction once it has completed.
+type QueryResult struct {
+	Duration     time.Duration
+	RowsAffected int64
+	Err          error
+	// ErrorClass is a low-cardinality description of Err, suitable for use as a metric label. It is empty if Err is nil.
+	ErrorClass string
+}
+
+// QueryTracer is notified of every query and transaction executed by the Database. The context returned by
+// TraceQueryStart is passed to the matching TraceQueryEnd call, so it may be used to carry spans.
+type QueryTracer interface {
+	TraceQueryStart(ctx context.Context, info QueryInfo) context.Context
+	TraceQueryEnd(ctx context.Context, info QueryInfo, result QueryResult)
+}
+
+type Option func(*options)
+
+type options struct {
+	tracers []QueryTracer
+}
+
+// WithTracer installs tracer on every query and transaction. It may be passed multiple times to install several tracers.
+func WithTracer(tracer QueryTracer) Option {
+	return func(o *options) {
+		o.tracers = append(o.tracers, tracer)
+	}
+}
+
+// WithSlowQueryLogger logs every query and transaction that takes longer than threshold to logger.
+func WithSlowQueryLogger(threshold time.Duration, logger logrus.FieldLogger) Option {
+	return WithTracer(NewSlowQueryLogger(threshold, logger))
+}
+
+func (o options) tracer() QueryTracer {
+	switch len(o.tracers) {
+	case 0:
+		return nil
+	case 1:
+		return o.tracers[0]
+	default:
+		return multiTracer(o.tracers)
+	}
+}
+
+type multiTracer []QueryTracer
+
+func (m multiTracer) TraceQueryStart(ctx context.Context, info QueryInfo) context.Context {
+	for _, tracer := range m {
+		ctx = tracer.TraceQueryStart(ctx, info)
+	}
+
+	return ctx
+}
+
+func (m multiTracer) TraceQueryEnd(ctx context.Context, info QueryInfo, result QueryResult) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].TraceQueryEnd(ctx, info, result)
+	}
+}
+
+type SlowQueryLogger struct {
+	threshold time.Duration
+	logger    logrus.FieldLogger
+}
+
+func NewSlowQueryLogger(threshold time.Duration, logger logrus.FieldLogger) *SlowQueryLogger {
+	return &SlowQueryLogger{
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+func (l *SlowQueryLogger) TraceQueryStart(ctx context.Context, _ QueryInfo) context.Context {
+	return ctx
+}
+
+func (l *SlowQueryLogger) TraceQueryEnd(_ context.Context, info QueryInfo, result QueryResult) {
+	if result.Duration < l.threshold {
+		return
+	}
+
+	fields := logrus.Fields{
+		"kind":          info.Kind,
+		"table":         info.Table,
+		"method":        info.Method,
+		"duration":      result.Duration,
+		"rows_affected": result.RowsAffected,
+	}
+
+	if info.SQL != "" {
+		fields["sql"] = info.SQL
+	}
+
+	if result.Err != nil {
+		fields["error_class"] = result.ErrorClass
+		fields["error"] = result.Err.Error()
+	}
+
+	l.logger.WithFields(fields).Warn("Slow query")
+}
+
+// ErrorClass returns a low-cardinality description of err, suitable for use as a metric label
+func ErrorClass(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "no_rows"
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return "unique_violation"
+		case "23503":
+			return "foreign_key_violation"
+		case "23514":
+			return "check_violation"
+		case "23502":
+			return "not_null_violation"
+		case "40001":
+			return "serialization_failure"
+		case "40P01":
+			return "deadlock_detected"
+		case "57014":
+			return "query_canceled"
+		default:
+			if len(pgErr.Code) >= 2 {
+				return "sqlstate_" + pgErr.Code[:2]
+			}
+
+			return "sqlstate_unknown"
+		}
+	}
+
+	return "other"
+}
@@ -0,0 +1,90 @@
+// Package oteltracer adapts database.QueryTracer to OpenTelemetry, producing a client span for every query and
+// transaction.
+package oteltracer
+
+import (
+	"context"
+	"github.com/TicketsBot/database"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/TicketsBot/database"
+
+type Tracer struct {
+	tracer     trace.Tracer
+	includeSQL bool
+}
+
+type Option func(*Tracer)
+
+// WithTracerProvider uses provider instead of the global tracer provider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithSQL records the query text in the db.statement attribute. Query parameters are never recorded.
+func WithSQL() Option {
+	return func(t *Tracer) {
+		t.includeSQL = true
+	}
+}
+
+func New(opts ...Option) *Tracer {
+	t := &Tracer{
+		tracer: otel.GetTracerProvider().Tracer(instrumentationName),
+	}
+
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+var _ database.QueryTracer = (*Tracer)(nil)
+
+func (t *Tracer) TraceQueryStart(ctx context.Context, info database.QueryInfo) context.Context {
+	attributes := []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation", string(info.Kind)),
+		attribute.String("db.tickets.table", info.Table),
+		attribute.String("db.tickets.method", info.Method),
+		attribute.Bool("db.tickets.in_transaction", info.InTransaction),
+	}
+
+	if t.includeSQL && info.SQL != "" {
+		attributes = append(attributes, attribute.String("db.statement", info.SQL))
+	}
+
+	ctx, _ = t.tracer.Start(ctx, spanName(info), trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	return ctx
+}
+
+func (t *Tracer) TraceQueryEnd(ctx context.Context, _ database.QueryInfo, result database.QueryResult) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int64("db.rows_affected", result.RowsAffected))
+
+	// No rows is an expected outcome for many queries, so is not considered an error
+	if result.Err != nil && result.ErrorClass != "no_rows" {
+		span.RecordError(result.Err)
+		span.SetStatus(codes.Error, result.ErrorClass)
+	}
+
+	span.End()
+}
+
+func spanName(info database.QueryInfo) string {
+	switch {
+	case info.Table != "" && info.Method != "":
+		return info.Table + "." + info.Method
+	case info.Method != "":
+		return info.Method
+	default:
+		return string(info.Kind)
+	}
+}
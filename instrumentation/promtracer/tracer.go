@@ -0,0 +1,67 @@
+// Package promtracer adapts database.QueryTracer to Prometheus, recording the duration and number of rows affected by
+// every query and transaction.
+package promtracer
+
+import (
+	"context"
+	"github.com/TicketsBot/database"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type Tracer struct {
+	duration     *prometheus.HistogramVec
+	rowsAffected *prometheus.HistogramVec
+}
+
+type Options struct {
+	Namespace string
+	// DurationBuckets defaults to prometheus.DefBuckets
+	DurationBuckets []float64
+}
+
+func New(registerer prometheus.Registerer, opts Options) (*Tracer, error) {
+	if opts.DurationBuckets == nil {
+		opts.DurationBuckets = prometheus.DefBuckets
+	}
+
+	t := &Tracer{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: "database",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of database queries and transactions.",
+			Buckets:   opts.DurationBuckets,
+		}, []string{"table", "method", "kind", "error_class"}),
+		rowsAffected: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: "database",
+			Name:      "query_rows_affected",
+			Help:      "Number of rows returned or affected by database queries.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"table", "method", "kind"}),
+	}
+
+	for _, collector := range []prometheus.Collector{t.duration, t.rowsAffected} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+var _ database.QueryTracer = (*Tracer)(nil)
+
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ database.QueryInfo) context.Context {
+	return ctx
+}
+
+func (t *Tracer) TraceQueryEnd(_ context.Context, info database.QueryInfo, result database.QueryResult) {
+	kind := string(info.Kind)
+
+	t.duration.WithLabelValues(info.Table, info.Method, kind, result.ErrorClass).Observe(result.Duration.Seconds())
+
+	if info.Kind != database.QueryKindTransaction && info.Kind != database.QueryKindBatch {
+		t.rowsAffected.WithLabelValues(info.Table, info.Method, kind).Observe(float64(result.RowsAffected))
+	}
+}
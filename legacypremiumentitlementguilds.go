@@ -5,7 +5,6 @@ import (
 	_ "embed"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type LegacyPremiumEntitlementGuildRecord struct {
@@ -15,7 +14,7 @@ type LegacyPremiumEntitlementGuildRecord struct {
 }
 
 type LegacyPremiumEntitlementGuilds struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -35,7 +34,7 @@ var (
 	legacyPremiumEntitlementGuildsDeleteByEntitlement string
 )
 
-func newLegacyPremiumEntitlementGuildsTable(db *pgxpool.Pool) *LegacyPremiumEntitlementGuilds {
+func newLegacyPremiumEntitlementGuildsTable(db *Pool) *LegacyPremiumEntitlementGuilds {
 	return &LegacyPremiumEntitlementGuilds{
 		db,
 	}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
@@ -20,10 +19,10 @@ type LegacyPremiumEntitlement struct {
 }
 
 type LegacyPremiumEntitlements struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newLegacyPremiumEntitlement(db *pgxpool.Pool) *LegacyPremiumEntitlements {
+func newLegacyPremiumEntitlement(db *Pool) *LegacyPremiumEntitlements {
 	return &LegacyPremiumEntitlements{
 		db,
 	}
@@ -84,7 +83,7 @@ func (e *LegacyPremiumEntitlements) ListAll(ctx context.Context, tx pgx.Tx) ([]L
 func (e *LegacyPremiumEntitlements) GetGuildTier(ctx context.Context, guildId, ownerId uint64, gracePeriod time.Duration) (int32, bool, error) {
 	var tier int32
 	if err := e.QueryRow(ctx, legacyPremiumEntitlementsGetGuildTier, guildId, ownerId, gracePeriod).Scan(&tier); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return -1, false, nil
 		}
 
@@ -104,7 +103,7 @@ func (e *LegacyPremiumEntitlements) GetUserTier(ctx context.Context, userId uint
 		&entitlement.IsLegacy,
 		&entitlement.ExpiresAt,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		}
 
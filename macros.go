@@ -0,0 +1,375 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/TicketsBot/common/model"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MacroActionType string
+
+const (
+	MacroActionSetStatus    MacroActionType = "set_status"
+	MacroActionAddLabel     MacroActionType = "add_label"
+	MacroActionClose        MacroActionType = "close"
+	MacroActionTransferTeam MacroActionType = "transfer_team"
+)
+
+// Macro is a canned response that can also change the ticket. The reply, made up of Content and Embed, is sent first,
+// after which the actions are performed in order. A macro is available in every ticket in the guild, unless TeamId is
+// set, in which case only members of the team can use it, or PanelId is set, in which case it can only be used in
+// tickets opened from the panel.
+type Macro struct {
+	Id         int                    `json:"id"`
+	GuildId    uint64                 `json:"guild_id,string"`
+	Name       string                 `json:"name"`
+	TeamId     *int                   `json:"team_id"`
+	PanelId    *int                   `json:"panel_id"`
+	Content    *string                `json:"content"`
+	Embed      *CustomEmbedWithFields `json:"embed"`
+	Actions    []MacroAction          `json:"actions"`
+	UsageCount int                    `json:"usage_count"`
+	LastUsedAt *time.Time             `json:"last_used_at"`
+}
+
+// MacroAction is a change made to the ticket when a macro is used. Which fields are used depends on the action: Status
+// for set_status, LabelId for add_label, CloseReason for close, and TeamId for transfer_team.
+type MacroAction struct {
+	Action      MacroActionType     `json:"action"`
+	Status      *model.TicketStatus `json:"status,omitempty"`
+	LabelId     *int                `json:"label_id,omitempty"`
+	CloseReason *string             `json:"close_reason,omitempty"`
+	TeamId      *int                `json:"team_id,omitempty"`
+}
+
+// MacroVariables holds the ticket data that can be substituted into the content of a macro
+type MacroVariables struct {
+	TicketId   int
+	UserId     uint64
+	ChannelId  *uint64
+	OpenTime   time.Time
+	ClaimedBy  *uint64
+	PanelTitle *string
+}
+
+type Macros struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/macros/schema.sql
+	macrosSchema string
+
+	// macrosImportTags turns the tags that existed before macros into guild-wide macros that only send a reply. It is
+	// run once as a migration, so that macros deleted afterwards are not recreated from their tag.
+	//go:embed sql/macros/import_tags.sql
+	macrosImportTags string
+
+	//go:embed sql/macros/get_available.sql
+	macrosGetAvailable string
+
+	//go:embed sql/macros/get_variables.sql
+	macrosGetVariables string
+)
+
+const macroColumns = `"id", "guild_id", "name", "team_id", "panel_id", "content", "embed", "usage_count", "last_used_at"`
+
+func newMacros(db *Pool) *Macros {
+	return &Macros{
+		db,
+	}
+}
+
+func (Macros) Schema() string {
+	return macrosSchema
+}
+
+func (m *Macros) Get(ctx context.Context, guildId uint64, macroId int) (Macro, error) {
+	query := `SELECT ` + macroColumns + ` FROM macros WHERE "id" = $1 AND "guild_id" = $2;`
+	return m.getOne(ctx, query, macroId, guildId)
+}
+
+// GetByName looks up a macro by its case-insensitive name
+func (m *Macros) GetByName(ctx context.Context, guildId uint64, name string) (Macro, error) {
+	query := `SELECT ` + macroColumns + ` FROM macros WHERE "guild_id" = $1 AND "name" = LOWER($2);`
+	return m.getOne(ctx, query, guildId, name)
+}
+
+// GetAll returns every macro in the guild, regardless of scope, ordered by name
+func (m *Macros) GetAll(ctx context.Context, guildId uint64) ([]Macro, error) {
+	query := `SELECT ` + macroColumns + ` FROM macros WHERE "guild_id" = $1 ORDER BY "name";`
+	return m.getMany(ctx, query, guildId)
+}
+
+// GetAvailable returns the macros that can be used in a ticket opened from panelId by a member of teamIds, with the
+// most used first. panelId is nil for tickets that were not opened from a panel.
+func (m *Macros) GetAvailable(ctx context.Context, guildId uint64, panelId *int, teamIds []int) ([]Macro, error) {
+	teamIdArray := &pgtype.Int4Array{}
+	if err := teamIdArray.Set(teamIds); err != nil {
+		return nil, err
+	}
+
+	return m.getMany(ctx, macrosGetAvailable, guildId, panelId, teamIdArray)
+}
+
+// Create returns ErrConflict if the guild already has a macro with the same name
+func (m *Macros) Create(ctx context.Context, macro Macro) (id int, err error) {
+	embedRaw, err := marshalMacroEmbed(macro.Embed)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := m.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO macros("guild_id", "name", "team_id", "panel_id", "content", "embed")
+VALUES($1, LOWER($2), $3, $4, $5, $6)
+RETURNING "id";`
+
+	if err := tx.QueryRow(ctx, query, macro.GuildId, macro.Name, macro.TeamId, macro.PanelId, macro.Content, embedRaw).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := m.setActionsTx(ctx, tx, id, macro.Actions); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Update replaces the name, scope, reply and actions of the macro. The usage count is retained.
+func (m *Macros) Update(ctx context.Context, macro Macro) error {
+	embedRaw, err := marshalMacroEmbed(macro.Embed)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := m.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+UPDATE macros
+SET "name" = LOWER($3), "team_id" = $4, "panel_id" = $5, "content" = $6, "embed" = $7
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	tag, err := tx.Exec(ctx, query, macro.Id, macro.GuildId, macro.Name, macro.TeamId, macro.PanelId, macro.Content, embedRaw)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := m.setActionsTx(ctx, tx, macro.Id, macro.Actions); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (m *Macros) Delete(ctx context.Context, guildId uint64, macroId int) (err error) {
+	_, err = m.Exec(ctx, `DELETE FROM macros WHERE "id" = $1 AND "guild_id" = $2;`, macroId, guildId)
+	return
+}
+
+// RecordUse increments the usage counter of the macro
+func (m *Macros) RecordUse(ctx context.Context, guildId uint64, macroId int) (err error) {
+	query := `UPDATE macros SET "usage_count" = "usage_count" + 1, "last_used_at" = NOW() WHERE "id" = $1 AND "guild_id" = $2;`
+	_, err = m.Exec(ctx, query, macroId, guildId)
+	return
+}
+
+// GetVariables loads the ticket data that can be substituted into a macro used in the ticket
+func (m *Macros) GetVariables(ctx context.Context, guildId uint64, ticketId int) (variables MacroVariables, err error) {
+	err = m.QueryRow(ctx, macrosGetVariables, guildId, ticketId).Scan(
+		&variables.TicketId,
+		&variables.UserId,
+		&variables.ChannelId,
+		&variables.OpenTime,
+		&variables.ClaimedBy,
+		&variables.PanelTitle,
+	)
+	return
+}
+
+// Resolve substitutes the variables into text. The supported variables are %ticket_id%, %user%, %user_id%,
+// %channel%, %open_time%, %claimed_by% and %panel%. Variables without a value, such as %claimed_by% in an unclaimed
+// ticket, are replaced with an empty string.
+func (v MacroVariables) Resolve(text string) string {
+	var channel, claimedBy, panel string
+	if v.ChannelId != nil {
+		channel = fmt.Sprintf("<#%d>", *v.ChannelId)
+	}
+
+	if v.ClaimedBy != nil {
+		claimedBy = fmt.Sprintf("<@%d>", *v.ClaimedBy)
+	}
+
+	if v.PanelTitle != nil {
+		panel = *v.PanelTitle
+	}
+
+	return strings.NewReplacer(
+		"%ticket_id%", strconv.Itoa(v.TicketId),
+		"%user%", fmt.Sprintf("<@%d>", v.UserId),
+		"%user_id%", strconv.FormatUint(v.UserId, 10),
+		"%channel%", channel,
+		"%open_time%", fmt.Sprintf("<t:%d:f>", v.OpenTime.Unix()),
+		"%claimed_by%", claimedBy,
+		"%panel%", panel,
+	).Replace(text)
+}
+
+func (m *Macros) setActionsTx(ctx context.Context, tx pgx.Tx, macroId int, actions []MacroAction) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM macro_actions WHERE "macro_id" = $1;`, macroId); err != nil {
+		return err
+	}
+
+	for position, action := range actions {
+		query := `
+INSERT INTO macro_actions("macro_id", "position", "action", "status", "label_id", "close_reason", "team_id")
+VALUES($1, $2, $3, $4, $5, $6, $7);`
+
+		if _, err := tx.Exec(ctx, query, macroId, position, action.Action, action.Status, action.LabelId, action.CloseReason, action.TeamId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Macros) getOne(ctx context.Context, query string, args ...interface{}) (Macro, error) {
+	macros, err := m.getMany(ctx, query, args...)
+	if err != nil {
+		return Macro{}, err
+	}
+
+	if len(macros) == 0 {
+		return Macro{}, ErrNotFound
+	}
+
+	return macros[0], nil
+}
+
+// getMany runs a query selecting macroColumns, and loads the actions of each macro returned
+func (m *Macros) getMany(ctx context.Context, query string, args ...interface{}) ([]Macro, error) {
+	rows, err := m.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	macros := make([]Macro, 0)
+	for rows.Next() {
+		var macro Macro
+		var embedRaw *string
+		if err := rows.Scan(
+			&macro.Id,
+			&macro.GuildId,
+			&macro.Name,
+			&macro.TeamId,
+			&macro.PanelId,
+			&macro.Content,
+			&embedRaw,
+			&macro.UsageCount,
+			&macro.LastUsedAt,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if embedRaw != nil {
+			if err := json.UnmarshalFromString(*embedRaw, &macro.Embed); err != nil {
+				rows.Close()
+				return nil, err
+			}
+		}
+
+		macro.Actions = make([]MacroAction, 0)
+		macros = append(macros, macro)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(macros) == 0 {
+		return macros, nil
+	}
+
+	ids := make([]int, len(macros))
+	byId := make(map[int]*Macro, len(macros))
+	for i := range macros {
+		ids[i] = macros[i].Id
+		byId[macros[i].Id] = &macros[i]
+	}
+
+	idArray := &pgtype.Int4Array{}
+	if err := idArray.Set(ids); err != nil {
+		return nil, err
+	}
+
+	actionsQuery := `
+SELECT "macro_id", "action", "status", "label_id", "close_reason", "team_id"
+FROM macro_actions
+WHERE "macro_id" = ANY($1)
+ORDER BY "macro_id", "position";`
+
+	rows, err = m.Query(ctx, actionsQuery, idArray)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var macroId int
+		var action MacroAction
+		if err := rows.Scan(&macroId, &action.Action, &action.Status, &action.LabelId, &action.CloseReason, &action.TeamId); err != nil {
+			return nil, err
+		}
+
+		byId[macroId].Actions = append(byId[macroId].Actions, action)
+	}
+
+	return macros, rows.Err()
+}
+
+func marshalMacroEmbed(embed *CustomEmbedWithFields) (*string, error) {
+	if embed == nil {
+		return nil, nil
+	}
+
+	raw, err := json.MarshalToString(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &raw, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// migration is a one-off change to existing data, such as a backfill, that must not be repeated each time the tables
+// are created. Each migration is run at most once per database, and is recorded in schema_migrations by name, so names
+// must never be reused.
+type migration struct {
+	name  string
+	query string
+}
+
+// migrations are run in order by CreateTables, after every table has been created
+var migrations = []migration{
+	{"ticket_id_counters_backfill", ticketIdCountersBackfill},
+	{"ticket_assignments_claims_backfill", ticketAssignmentsClaimsBackfill},
+	{"ticket_last_message_unanswered_since_backfill", ticketLastMessageUnansweredSinceBackfill},
+	{"macros_import_tags", macrosImportTags},
+}
+
+func mustMigrate(ctx context.Context, pool *pgxpool.Pool, migrations ...migration) {
+	query := `
+CREATE TABLE IF NOT EXISTS schema_migrations(
+	"name" VARCHAR(64) NOT NULL,
+	"applied_at" timestamptz NOT NULL DEFAULT NOW(),
+	PRIMARY KEY("name")
+);`
+
+	if _, err := pool.Exec(ctx, query); err != nil {
+		panic(err)
+	}
+
+	for _, migration := range migrations {
+		if err := runMigration(ctx, pool, migration); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// runMigration records the migration before running it, in the same transaction. If another instance is running the
+// same migration, the insert waits for it to finish, and then does nothing if it committed.
+func runMigration(ctx context.Context, pool *pgxpool.Pool, migration migration) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, `INSERT INTO schema_migrations("name") VALUES($1) ON CONFLICT("name") DO NOTHING;`, migration.name)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, migration.query); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
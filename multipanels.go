@@ -3,8 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type MultiPanel struct {
@@ -18,10 +16,10 @@ type MultiPanel struct {
 }
 
 type MultiPanelTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newMultiMultiPanelTable(db *pgxpool.Pool) *MultiPanelTable {
+func newMultiMultiPanelTable(db *Pool) *MultiPanelTable {
 	return &MultiPanelTable{
 		db,
 	}
@@ -60,7 +58,7 @@ WHERE
 	)
 
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return MultiPanel{}, false, nil
 		} else {
 			return MultiPanel{}, false, err
@@ -93,7 +91,7 @@ WHERE
 	)
 
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return MultiPanel{}, false, nil
 		} else {
 			return MultiPanel{}, false, err
@@ -117,11 +115,12 @@ WHERE "guild_id" = $1;
 `
 
 	rows, err := p.Query(ctx, query, guildId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var panels []MultiPanel
 	for rows.Next() {
 		var panel MultiPanel
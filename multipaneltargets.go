@@ -1,15 +1,12 @@
 package database
 
-import (
-	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
-)
+import "context"
 
 type MultiPanelTargets struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newMultiPanelTargets(db *pgxpool.Pool) *MultiPanelTargets {
+func newMultiPanelTargets(db *Pool) *MultiPanelTargets {
 	return &MultiPanelTargets{
 		db,
 	}
@@ -60,12 +57,13 @@ ON panels.panel_id = multi_panel_targets.panel_id
 WHERE "multi_panel_id" = $1;`
 
 	rows, err := p.Query(ctx, query, multiPanelId)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var panel Panel
 		if err := rows.Scan(panel.fieldPtrs()...); err != nil {
@@ -95,11 +93,12 @@ WHERE multi_panel_targets.panel_id = $1;
 `
 
 	rows, err := p.Query(ctx, query, panelId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var multiPanels []MultiPanel
 	for rows.Next() {
 		var multiPanel MultiPanel
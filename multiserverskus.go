@@ -6,11 +6,10 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type MultiServerSkus struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -21,7 +20,7 @@ var (
 	multiServerSkusGetPermittedServerCount string
 )
 
-func newMultiServerSkusTable(db *pgxpool.Pool) *MultiServerSkus {
+func newMultiServerSkusTable(db *Pool) *MultiServerSkus {
 	return &MultiServerSkus{
 		db,
 	}
@@ -34,7 +33,7 @@ func (MultiServerSkus) Schema() string {
 func (m *MultiServerSkus) GetPermittedServerCount(ctx context.Context, tx pgx.Tx, skuId uuid.UUID) (int, bool, error) {
 	var count int
 	if err := tx.QueryRow(ctx, multiServerSkusGetPermittedServerCount, skuId).Scan(&count); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return 0, false, nil
 		}
 
@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type NamingScheme string
@@ -14,10 +13,10 @@ const (
 )
 
 type TicketNamingScheme struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newTicketNamingScheme(db *pgxpool.Pool) *TicketNamingScheme {
+func newTicketNamingScheme(db *Pool) *TicketNamingScheme {
 	return &TicketNamingScheme{
 		db,
 	}
@@ -36,7 +35,7 @@ func (t *TicketNamingScheme) Get(ctx context.Context, guildId uint64) (ns Naming
 	query := `SELECT "naming_scheme" from naming_scheme WHERE "guild_id" = $1`
 
 	var namingScheme string
-	if err := t.QueryRow(ctx, query, guildId).Scan(&namingScheme); err != nil && err != pgx.ErrNoRows {
+	if err := t.QueryRow(ctx, query, guildId).Scan(&namingScheme); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
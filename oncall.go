@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type OnCall struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newOnCall(db *pgxpool.Pool) *OnCall {
+func newOnCall(db *Pool) *OnCall {
 	return &OnCall{
 		db,
 	}
@@ -31,7 +30,7 @@ func (b *OnCall) IsOnCall(ctx context.Context, guildId, userId uint64) (bool, er
 
 	var onCall bool
 	if err := b.QueryRow(ctx, query, guildId, userId).Scan(&onCall); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil
 		} else {
 			return false, err
@@ -45,11 +44,12 @@ func (b *OnCall) GetUsersOnCall(ctx context.Context, guildId uint64) ([]uint64,
 	query := `SELECT "user_id" FROM on_call WHERE "guild_id" = $1 AND "is_on_call" = true;`
 
 	rows, err := b.Query(ctx, query, guildId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var users []uint64
 	for rows.Next() {
 		var userId uint64
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// OnCallHoliday is a date, in the schedule's timezone, on which nobody is on call unless an override is active
+type OnCallHoliday struct {
+	ScheduleId int       `json:"schedule_id"`
+	Date       time.Time `json:"date"`
+	Name       *string   `json:"name"`
+}
+
+type OnCallHolidays struct {
+	*Pool
+}
+
+func newOnCallHolidays(db *Pool) *OnCallHolidays {
+	return &OnCallHolidays{
+		db,
+	}
+}
+
+func (OnCallHolidays) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS on_call_holidays(
+	"schedule_id" int NOT NULL,
+	"date" date NOT NULL,
+	"name" VARCHAR(64) DEFAULT NULL,
+	FOREIGN KEY("schedule_id") REFERENCES on_call_schedules("id") ON DELETE CASCADE,
+	PRIMARY KEY("schedule_id", "date")
+);
+`
+}
+
+// GetAll returns no holidays if the schedule does not belong to the guild
+func (h *OnCallHolidays) GetAll(ctx context.Context, guildId uint64, scheduleId int) ([]OnCallHoliday, error) {
+	query := `
+SELECT "schedule_id", "date", "name"
+FROM on_call_holidays
+WHERE "schedule_id" = $1 AND "schedule_id" IN (SELECT "id" FROM on_call_schedules WHERE "guild_id" = $2)
+ORDER BY "date";`
+
+	rows, err := h.Query(ctx, query, scheduleId, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	holidays := make([]OnCallHoliday, 0)
+	for rows.Next() {
+		var holiday OnCallHoliday
+		if err := rows.Scan(&holiday.ScheduleId, &holiday.Date, &holiday.Name); err != nil {
+			return nil, err
+		}
+
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, rows.Err()
+}
+
+// Set adds the holiday, or renames it if it already exists. Only the date of holiday.Date is stored. ErrNotFound is
+// returned if the holiday's schedule does not belong to the guild.
+func (h *OnCallHolidays) Set(ctx context.Context, guildId uint64, holiday OnCallHoliday) error {
+	query := `
+INSERT INTO on_call_holidays("schedule_id", "date", "name")
+SELECT "id", $2::date, $3::varchar
+FROM on_call_schedules
+WHERE "id" = $1 AND "guild_id" = $4
+ON CONFLICT("schedule_id", "date") DO UPDATE SET "name" = EXCLUDED.name;`
+
+	res, err := h.Exec(ctx, query, holiday.ScheduleId, holiday.Date.Format(dateLayout), holiday.Name, guildId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (h *OnCallHolidays) Delete(ctx context.Context, guildId uint64, scheduleId int, date time.Time) (err error) {
+	query := `
+DELETE FROM on_call_holidays
+WHERE "schedule_id" = $1 AND "date" = $2::date AND "schedule_id" IN (SELECT "id" FROM on_call_schedules WHERE "guild_id" = $3);`
+
+	_, err = h.Exec(ctx, query, scheduleId, date.Format(dateLayout), guildId)
+	return
+}
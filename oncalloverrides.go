@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// OnCallOverride puts a user on call for a schedule between StartsAt and EndsAt, in place of the rotation
+type OnCallOverride struct {
+	Id         int       `json:"id"`
+	ScheduleId int       `json:"schedule_id"`
+	UserId     uint64    `json:"user_id,string"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+}
+
+type OnCallOverrides struct {
+	*Pool
+}
+
+func newOnCallOverrides(db *Pool) *OnCallOverrides {
+	return &OnCallOverrides{
+		db,
+	}
+}
+
+func (OnCallOverrides) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS on_call_overrides(
+	"id" SERIAL NOT NULL UNIQUE,
+	"schedule_id" int NOT NULL,
+	"user_id" int8 NOT NULL,
+	"starts_at" timestamptz NOT NULL,
+	"ends_at" timestamptz NOT NULL,
+	CONSTRAINT on_call_overrides_time_order CHECK ("ends_at" > "starts_at"),
+	FOREIGN KEY("schedule_id") REFERENCES on_call_schedules("id") ON DELETE CASCADE,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS on_call_overrides_schedule_id_ends_at ON on_call_overrides("schedule_id", "ends_at");
+`
+}
+
+// GetUpcoming returns the overrides of the schedule that have not yet ended. No overrides are returned if the schedule
+// does not belong to the guild.
+func (o *OnCallOverrides) GetUpcoming(ctx context.Context, guildId uint64, scheduleId int) ([]OnCallOverride, error) {
+	query := `
+SELECT "id", "schedule_id", "user_id", "starts_at", "ends_at"
+FROM on_call_overrides
+WHERE "schedule_id" = $1 AND "ends_at" > NOW() AND "schedule_id" IN (SELECT "id" FROM on_call_schedules WHERE "guild_id" = $2)
+ORDER BY "starts_at";`
+
+	rows, err := o.Query(ctx, query, scheduleId, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	overrides := make([]OnCallOverride, 0)
+	for rows.Next() {
+		var override OnCallOverride
+		if err := rows.Scan(&override.Id, &override.ScheduleId, &override.UserId, &override.StartsAt, &override.EndsAt); err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// Create returns ErrNotFound if the override's schedule does not belong to the guild
+func (o *OnCallOverrides) Create(ctx context.Context, guildId uint64, override OnCallOverride) (id int, err error) {
+	query := `
+INSERT INTO on_call_overrides("schedule_id", "user_id", "starts_at", "ends_at")
+SELECT "id", $2, $3, $4
+FROM on_call_schedules
+WHERE "id" = $1 AND "guild_id" = $5
+RETURNING "id";`
+
+	err = o.QueryRow(ctx, query, override.ScheduleId, override.UserId, override.StartsAt, override.EndsAt, guildId).Scan(&id)
+	return
+}
+
+func (o *OnCallOverrides) Delete(ctx context.Context, guildId uint64, scheduleId, overrideId int) (err error) {
+	query := `
+DELETE FROM on_call_overrides
+WHERE "id" = $1 AND "schedule_id" = $2 AND "schedule_id" IN (SELECT "id" FROM on_call_schedules WHERE "guild_id" = $3);`
+
+	_, err = o.Exec(ctx, query, overrideId, scheduleId, guildId)
+	return
+}
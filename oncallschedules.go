@@ -0,0 +1,419 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgtype"
+	"sort"
+	"time"
+)
+
+// OnCallSchedule is a recurring on-call rotation for a support team, or for the guild's default team if TeamId is nil.
+// Members take turns to be on call for RotationDays days each, starting with the first member on RotationStart. Shifts
+// change at HandoffMinute minutes past midnight in Timezone, so a weekly rotation has a RotationDays of 7.
+//
+// Overrides in OnCallOverrides take precedence over the rotation, and nobody is on call on the schedule's holidays in
+// OnCallHolidays unless an override is active.
+type OnCallSchedule struct {
+	Id            int       `json:"id"`
+	GuildId       uint64    `json:"guild_id,string"`
+	TeamId        *int      `json:"team_id"`
+	Timezone      string    `json:"timezone"`
+	RotationStart time.Time `json:"rotation_start"`
+	RotationDays  int       `json:"rotation_days"`
+	HandoffMinute int       `json:"handoff_minute"`
+	Members       []uint64  `json:"members"`
+}
+
+// OnCallShiftTransition is a change in who is on call for a schedule
+type OnCallShiftTransition struct {
+	ScheduleId int       `json:"schedule_id"`
+	GuildId    uint64    `json:"guild_id,string"`
+	TeamId     *int      `json:"team_id"`
+	At         time.Time `json:"at"`
+	From       []uint64  `json:"from"`
+	To         []uint64  `json:"to"`
+}
+
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+type OnCallSchedules struct {
+	*Pool
+}
+
+func newOnCallSchedules(db *Pool) *OnCallSchedules {
+	return &OnCallSchedules{
+		db,
+	}
+}
+
+func (OnCallSchedules) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS on_call_schedules(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"team_id" int DEFAULT NULL,
+	"timezone" VARCHAR(64) NOT NULL,
+	"rotation_start" date NOT NULL,
+	"rotation_days" int2 NOT NULL DEFAULT 7,
+	"handoff_minute" int2 NOT NULL DEFAULT 0,
+	"members" int8[] NOT NULL DEFAULT '{}',
+	CONSTRAINT on_call_schedules_rotation_days_positive CHECK ("rotation_days" >= 1),
+	CONSTRAINT on_call_schedules_handoff_minute_range CHECK ("handoff_minute" >= 0 AND "handoff_minute" < 1440),
+	FOREIGN KEY("team_id") REFERENCES support_team("id") ON DELETE CASCADE ON UPDATE CASCADE,
+	UNIQUE NULLS NOT DISTINCT("guild_id", "team_id"),
+	PRIMARY KEY("id")
+);
+`
+}
+
+const onCallScheduleColumns = `"id", "guild_id", "team_id", "timezone", "rotation_start", "rotation_days", "handoff_minute", "members"`
+
+func (s *OnCallSchedules) Get(ctx context.Context, guildId uint64, teamId *int) (schedule OnCallSchedule, err error) {
+	query := `SELECT ` + onCallScheduleColumns + ` FROM on_call_schedules WHERE "guild_id" = $1 AND "team_id" IS NOT DISTINCT FROM $2;`
+	err = s.QueryRow(ctx, query, guildId, teamId).Scan(schedule.fieldPtrs()...)
+	return
+}
+
+func (s *OnCallSchedules) GetAll(ctx context.Context, guildId uint64) ([]OnCallSchedule, error) {
+	query := `SELECT ` + onCallScheduleColumns + ` FROM on_call_schedules WHERE "guild_id" = $1 ORDER BY "id";`
+	return s.query(ctx, query, guildId)
+}
+
+// Set creates or replaces the schedule of the team, or of the guild if TeamId is nil. An error wrapping
+// ErrInvalidTimezone is returned if the timezone is not a valid IANA timezone.
+func (s *OnCallSchedules) Set(ctx context.Context, schedule OnCallSchedule) (id int, err error) {
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTimezone, schedule.Timezone)
+	}
+
+	members := &pgtype.Int8Array{}
+	if err := members.Set(nonNilUint64s(schedule.Members)); err != nil {
+		return 0, err
+	}
+
+	query := `
+INSERT INTO on_call_schedules("guild_id", "team_id", "timezone", "rotation_start", "rotation_days", "handoff_minute", "members")
+VALUES($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT("guild_id", "team_id") DO UPDATE
+SET "timezone" = $3, "rotation_start" = $4, "rotation_days" = $5, "handoff_minute" = $6, "members" = $7
+RETURNING "id";`
+
+	err = s.QueryRow(ctx, query,
+		schedule.GuildId,
+		schedule.TeamId,
+		schedule.Timezone,
+		schedule.RotationStart,
+		schedule.RotationDays,
+		schedule.HandoffMinute,
+		members,
+	).Scan(&id)
+	return
+}
+
+func (s *OnCallSchedules) Delete(ctx context.Context, guildId uint64, teamId *int) (err error) {
+	_, err = s.Exec(ctx, `DELETE FROM on_call_schedules WHERE "guild_id" = $1 AND "team_id" IS NOT DISTINCT FROM $2;`, guildId, teamId)
+	return
+}
+
+// WhoIsOnCall returns the users on call for the team at the given time, or for the guild's default team if teamId is
+// nil. ErrNotFound is returned if there is no schedule.
+func (s *OnCallSchedules) WhoIsOnCall(ctx context.Context, guildId uint64, teamId *int, at time.Time) ([]uint64, error) {
+	schedule, err := s.Get(ctx, guildId, teamId)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.loadState(ctx, []OnCallSchedule{schedule}, at, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return state[0].usersAt(at), nil
+}
+
+// GetTransitions returns every change in who is on call across all schedules in [from, to), ordered by time. A worker
+// can call it periodically to add and remove the on-call roles as shifts change.
+func (s *OnCallSchedules) GetTransitions(ctx context.Context, from, to time.Time) ([]OnCallShiftTransition, error) {
+	schedules, err := s.query(ctx, `SELECT `+onCallScheduleColumns+` FROM on_call_schedules ORDER BY "id";`)
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := s.loadState(ctx, schedules, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	transitions := make([]OnCallShiftTransition, 0)
+	for _, state := range states {
+		transitions = append(transitions, state.transitions(from, to)...)
+	}
+
+	sort.SliceStable(transitions, func(i, j int) bool {
+		return transitions[i].At.Before(transitions[j].At)
+	})
+
+	return transitions, nil
+}
+
+// loadState fetches the overrides and holidays of the schedules that apply between from and to
+func (s *OnCallSchedules) loadState(ctx context.Context, schedules []OnCallSchedule, from, to time.Time) ([]onCallScheduleState, error) {
+	ids := make([]int, len(schedules))
+	states := make([]onCallScheduleState, len(schedules))
+	byId := make(map[int]*onCallScheduleState, len(schedules))
+	for i, schedule := range schedules {
+		location, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, schedule.Timezone)
+		}
+
+		ids[i] = schedule.Id
+		states[i] = onCallScheduleState{
+			schedule: schedule,
+			location: location,
+			holidays: make(map[string]bool),
+		}
+		byId[schedule.Id] = &states[i]
+	}
+
+	idArray := &pgtype.Int4Array{}
+	if err := idArray.Set(ids); err != nil {
+		return nil, err
+	}
+
+	overridesQuery := `
+SELECT "schedule_id", "user_id", "starts_at", "ends_at"
+FROM on_call_overrides
+WHERE "schedule_id" = ANY($1) AND "starts_at" <= $3 AND "ends_at" > $2
+ORDER BY "starts_at";`
+
+	rows, err := s.Query(ctx, overridesQuery, idArray, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var override OnCallOverride
+		if err := rows.Scan(&override.ScheduleId, &override.UserId, &override.StartsAt, &override.EndsAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		byId[override.ScheduleId].overrides = append(byId[override.ScheduleId].overrides, override)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Holidays are local dates, so include a day either side to allow for the timezone offset
+	holidaysQuery := `
+SELECT "schedule_id", "date"
+FROM on_call_holidays
+WHERE "schedule_id" = ANY($1) AND "date" BETWEEN $2::date - 1 AND $3::date + 1;`
+
+	rows, err = s.Query(ctx, holidaysQuery, idArray, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var scheduleId int
+		var date time.Time
+		if err := rows.Scan(&scheduleId, &date); err != nil {
+			return nil, err
+		}
+
+		byId[scheduleId].holidays[date.Format(dateLayout)] = true
+	}
+
+	return states, rows.Err()
+}
+
+func (s *OnCallSchedules) query(ctx context.Context, query string, args ...interface{}) ([]OnCallSchedule, error) {
+	rows, err := s.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	schedules := make([]OnCallSchedule, 0)
+	for rows.Next() {
+		var schedule OnCallSchedule
+		if err := rows.Scan(schedule.fieldPtrs()...); err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (s *OnCallSchedule) fieldPtrs() []interface{} {
+	return []interface{}{
+		&s.Id,
+		&s.GuildId,
+		&s.TeamId,
+		&s.Timezone,
+		&s.RotationStart,
+		&s.RotationDays,
+		&s.HandoffMinute,
+		&s.Members,
+	}
+}
+
+const dateLayout = "2006-01-02"
+
+type onCallScheduleState struct {
+	schedule  OnCallSchedule
+	location  *time.Location
+	overrides []OnCallOverride
+	holidays  map[string]bool
+}
+
+func (s onCallScheduleState) usersAt(at time.Time) []uint64 {
+	users := make([]uint64, 0)
+	for _, override := range s.overrides {
+		if !at.Before(override.StartsAt) && at.Before(override.EndsAt) && !containsUint64(users, override.UserId) {
+			users = append(users, override.UserId)
+		}
+	}
+
+	if len(users) > 0 || len(s.schedule.Members) == 0 {
+		return users
+	}
+
+	if s.holidays[at.In(s.location).Format(dateLayout)] {
+		return users
+	}
+
+	shift := s.shiftIndex(at)
+	member := s.schedule.Members[((shift%len(s.schedule.Members))+len(s.schedule.Members))%len(s.schedule.Members)]
+	return append(users, member)
+}
+
+// shiftIndex returns the number of shifts that have started between the rotation start and the given time
+func (s onCallScheduleState) shiftIndex(at time.Time) int {
+	local := at.In(s.location)
+	date := civilDate(local)
+	if local.Hour()*60+local.Minute() < s.schedule.HandoffMinute {
+		date = date.AddDate(0, 0, -1)
+	}
+
+	days := int(date.Sub(civilDate(s.schedule.RotationStart)).Hours() / 24)
+	return floorDiv(days, s.schedule.RotationDays)
+}
+
+// shiftStart returns the time at which the given shift starts
+func (s onCallScheduleState) shiftStart(shift int) time.Time {
+	start := s.schedule.RotationStart
+	return time.Date(start.Year(), start.Month(), start.Day()+shift*s.schedule.RotationDays, 0, s.schedule.HandoffMinute, 0, 0, s.location)
+}
+
+// transitions evaluates who is on call at each point in [from, to) where it could change: shift handoffs, the start
+// and end of overrides, and the start and end of holidays
+func (s onCallScheduleState) transitions(from, to time.Time) []OnCallShiftTransition {
+	var boundaries []time.Time
+	for shift := s.shiftIndex(from) + 1; ; shift++ {
+		start := s.shiftStart(shift)
+		if !start.Before(to) {
+			break
+		}
+
+		boundaries = append(boundaries, start)
+	}
+
+	for _, override := range s.overrides {
+		boundaries = append(boundaries, override.StartsAt, override.EndsAt)
+	}
+
+	for date := range s.holidays {
+		day, err := time.ParseInLocation(dateLayout, date, s.location)
+		if err != nil {
+			continue
+		}
+
+		boundaries = append(boundaries, day, day.AddDate(0, 0, 1))
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+
+	var transitions []OnCallShiftTransition
+	current := s.usersAt(from)
+	for _, boundary := range boundaries {
+		if boundary.Before(from) || !boundary.Before(to) {
+			continue
+		}
+
+		next := s.usersAt(boundary)
+		if equalUint64s(current, next) {
+			continue
+		}
+
+		transitions = append(transitions, OnCallShiftTransition{
+			ScheduleId: s.schedule.Id,
+			GuildId:    s.schedule.GuildId,
+			TeamId:     s.schedule.TeamId,
+			At:         boundary,
+			From:       current,
+			To:         next,
+		})
+		current = next
+	}
+
+	return transitions
+}
+
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
+
+func containsUint64(s []uint64, v uint64) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equalUint64s(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, v := range a {
+		if !containsUint64(b, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nonNilUint64s(s []uint64) []uint64 {
+	if s == nil {
+		return []uint64{}
+	}
+
+	return s
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+)
+
+type OpenTicketRequest struct {
+	GuildId uint64
+	UserId  uint64
+	// Roles held by the user, which should include the @everyone role (the guild ID), as access control rules may
+	// reference it
+	Roles    []uint64
+	PanelId  *int
+	IsThread bool
+	// Members are added to the ticket, in addition to the user opening it
+	Members []uint64
+}
+
+type TicketRejectionReason string
+
+const (
+	TicketRejectionBlacklisted       TicketRejectionReason = "blacklisted"
+	TicketRejectionGlobalBlacklisted TicketRejectionReason = "global_blacklisted"
+	TicketRejectionLimitReached      TicketRejectionReason = "limit_reached"
+	TicketRejectionPanelDisabled     TicketRejectionReason = "panel_disabled"
+	TicketRejectionAccessDenied      TicketRejectionReason = "access_denied"
+)
+
+var ErrTicketRejected = errors.New("ticket open rejected")
+
+// TicketRejectedError is returned by OpenTicket when the user is not permitted to open the ticket.
+// errors.Is(err, ErrTicketRejected) holds.
+type TicketRejectedError struct {
+	Reason TicketRejectionReason
+	// ExceededLimit is set when Reason is TicketRejectionLimitReached
+	ExceededLimit *ExceededTicketLimit
+	// RoleId is the blacklisted role, or the role whose access control rule denied access, if any
+	RoleId *uint64
+}
+
+func (e *TicketRejectedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTicketRejected.Error(), e.Reason)
+}
+
+func (e *TicketRejectedError) Is(target error) bool {
+	return target == ErrTicketRejected
+}
+
+// openTicketMaxAttempts is the number of times the transaction is attempted if it fails to serialise
+const openTicketMaxAttempts = 3
+
+// OpenTicket checks blacklists, ticket limits (see TicketLimitRules.CheckLimits) and the panel's access control
+// rules, and then creates the ticket, all within a single serialisable transaction. A *TicketRejectedError is
+// returned if the user may not open the ticket.
+func (d *Database) OpenTicket(ctx context.Context, req OpenTicketRequest) (ticketId int, err error) {
+	for attempt := 1; attempt <= openTicketMaxAttempts; attempt++ {
+		ticketId, err = d.tryOpenTicket(ctx, req)
+		if !isSerializationFailure(err) {
+			return
+		}
+	}
+
+	return
+}
+
+func (d *Database) tryOpenTicket(ctx context.Context, req OpenTicketRequest) (int, error) {
+	tx, err := d.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := checkCanOpenTicket(ctx, tx, req); err != nil {
+		return 0, err
+	}
+
+	ticketId, err := d.Tickets.CreateTx(ctx, tx, req.GuildId, req.UserId, req.IsThread, req.PanelId)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userId := range req.Members {
+		query := `INSERT INTO ticket_members("guild_id", "ticket_id", "user_id") VALUES($1, $2, $3) ON CONFLICT("guild_id", "ticket_id", "user_id") DO NOTHING;`
+		if _, err := tx.Exec(ctx, query, req.GuildId, ticketId, userId); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return ticketId, nil
+}
+
+func checkCanOpenTicket(ctx context.Context, tx pgx.Tx, req OpenTicketRequest) error {
+	roles := &pgtype.Int8Array{}
+	if err := roles.Set(req.Roles); err != nil {
+		return err
+	}
+
+	query := `
+SELECT
+	EXISTS(SELECT 1 FROM global_blacklist WHERE "user_id" = $2),
+	EXISTS(SELECT 1 FROM blacklist WHERE "guild_id" = $1 AND "user_id" = $2),
+	(SELECT "role_id" FROM role_blacklist WHERE "guild_id" = $1 AND "role_id" = ANY($3) LIMIT 1);`
+
+	var globalBlacklisted, blacklisted bool
+	var blacklistedRole *uint64
+	if err := tx.QueryRow(ctx, query, req.GuildId, req.UserId, roles).Scan(
+		&globalBlacklisted,
+		&blacklisted,
+		&blacklistedRole,
+	); err != nil {
+		return err
+	}
+
+	if globalBlacklisted {
+		return &TicketRejectedError{Reason: TicketRejectionGlobalBlacklisted}
+	}
+
+	if blacklisted || blacklistedRole != nil {
+		return &TicketRejectedError{Reason: TicketRejectionBlacklisted, RoleId: blacklistedRole}
+	}
+
+	exceeded, err := checkTicketLimits(ctx, tx, req.GuildId, req.UserId, req.PanelId, req.Roles)
+	if err != nil {
+		return err
+	}
+
+	if exceeded != nil {
+		return &TicketRejectedError{Reason: TicketRejectionLimitReached, ExceededLimit: exceeded}
+	}
+
+	if req.PanelId == nil {
+		return nil
+	}
+
+	var disabled bool
+	query = `SELECT "disabled" OR "force_disabled" FROM panels WHERE "panel_id" = $1 AND "guild_id" = $2;`
+	if err := tx.QueryRow(ctx, query, *req.PanelId, req.GuildId).Scan(&disabled); err != nil {
+		return err
+	}
+
+	if disabled {
+		return &TicketRejectedError{Reason: TicketRejectionPanelDisabled}
+	}
+
+	var roleId uint64
+	var action AccessControlAction
+	if err := tx.QueryRow(ctx, panelAccessControlRulesGetFirstMatched, *req.PanelId, roles).Scan(&roleId, &action); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &TicketRejectedError{Reason: TicketRejectionAccessDenied}
+		}
+
+		return err
+	}
+
+	if action != AccessControlActionAllow {
+		return &TicketRejectedError{Reason: TicketRejectionAccessDenied, RoleId: &roleId}
+	}
+
+	return nil
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type AccessControlAction string
@@ -24,10 +23,10 @@ type PanelAccessControlRule struct {
 }
 
 type PanelAccessControlRules struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPanelAccessControlRules(db *pgxpool.Pool) *PanelAccessControlRules {
+func newPanelAccessControlRules(db *Pool) *PanelAccessControlRules {
 	return &PanelAccessControlRules{
 		db,
 	}
@@ -110,7 +109,7 @@ func (p *PanelAccessControlRules) GetFirstMatched(ctx context.Context, panelId i
 	var roleId uint64
 	var action AccessControlAction
 	if err := p.QueryRow(ctx, panelAccessControlRulesGetFirstMatched, panelId, idArray).Scan(&roleId, &action); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return 0, "", ErrNoRuleMatched
 		}
 
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PanelAutoCloseSettings overrides the guild's AutoCloseSettings for tickets opened from the panel. Nil fields fall
+// back to the guild's settings.
+type PanelAutoCloseSettings struct {
+	Enabled                 *bool          `json:"enabled"`
+	SinceOpenWithNoResponse *time.Duration `json:"since_open_with_no_response"`
+	SinceLastMessage        *time.Duration `json:"since_last_message"`
+}
+
+type PanelAutoClose struct {
+	*Pool
+}
+
+func newPanelAutoClose(db *Pool) *PanelAutoClose {
+	return &PanelAutoClose{
+		db,
+	}
+}
+
+func (PanelAutoClose) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS panel_auto_close(
+	"panel_id" int NOT NULL,
+	"enabled" bool DEFAULT NULL,
+	"since_open_with_no_response" interval DEFAULT NULL,
+	"since_last_message" interval DEFAULT NULL,
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE ON UPDATE CASCADE,
+	PRIMARY KEY("panel_id")
+);
+`
+}
+
+func (p *PanelAutoClose) Get(ctx context.Context, panelId int) (settings PanelAutoCloseSettings, e error) {
+	query := `SELECT "enabled", "since_open_with_no_response", "since_last_message" FROM panel_auto_close WHERE "panel_id" = $1;`
+	if err := p.QueryRow(ctx, query, panelId).Scan(&settings.Enabled, &settings.SinceOpenWithNoResponse, &settings.SinceLastMessage); err != nil && !errors.Is(err, ErrNotFound) { // defaults to nil if no rows
+		e = err
+	}
+
+	return
+}
+
+func (p *PanelAutoClose) Set(ctx context.Context, panelId int, settings PanelAutoCloseSettings) (err error) {
+	query := `
+INSERT INTO panel_auto_close("panel_id", "enabled", "since_open_with_no_response", "since_last_message")
+VALUES($1, $2, $3, $4)
+ON CONFLICT("panel_id") DO UPDATE
+SET "enabled" = $2, "since_open_with_no_response" = $3, "since_last_message" = $4;`
+
+	_, err = p.Exec(ctx, query, panelId, settings.Enabled, settings.SinceOpenWithNoResponse, settings.SinceLastMessage)
+	return
+}
+
+func (p *PanelAutoClose) Delete(ctx context.Context, panelId int) (err error) {
+	_, err = p.Exec(ctx, `DELETE FROM panel_auto_close WHERE "panel_id" = $1;`, panelId)
+	return
+}
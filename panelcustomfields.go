@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText   CustomFieldType = "text"
+	CustomFieldTypeNumber CustomFieldType = "number"
+	CustomFieldTypeSelect CustomFieldType = "select"
+	CustomFieldTypeDate   CustomFieldType = "date"
+)
+
+// PanelCustomField defines a typed field, values for which are stored against each ticket opened from the panel in
+// TicketCustomFieldValues. Options lists the permitted values of select fields.
+type PanelCustomField struct {
+	Id       int             `json:"id"`
+	PanelId  int             `json:"panel_id"`
+	Name     string          `json:"name"`
+	Type     CustomFieldType `json:"type"`
+	Options  []string        `json:"options"`
+	Required bool            `json:"required"`
+	Position int             `json:"position"`
+}
+
+// CustomFieldValue holds the value of a custom field. Exactly one of Text, Number and Date is set: Text for text and
+// select fields, Number for number fields and Date for date fields.
+type CustomFieldValue struct {
+	FieldId int        `json:"field_id"`
+	Text    *string    `json:"text,omitempty"`
+	Number  *float64   `json:"number,omitempty"`
+	Date    *time.Time `json:"date,omitempty"`
+}
+
+var ErrInvalidCustomFieldValue = errors.New("invalid custom field value")
+
+// Validate returns an error wrapping ErrInvalidCustomFieldValue if the value does not match the field's type
+func (f PanelCustomField) Validate(value CustomFieldValue) error {
+	var ok bool
+	switch f.Type {
+	case CustomFieldTypeText:
+		ok = value.Text != nil && value.Number == nil && value.Date == nil
+	case CustomFieldTypeNumber:
+		ok = value.Text == nil && value.Number != nil && value.Date == nil
+	case CustomFieldTypeDate:
+		ok = value.Text == nil && value.Number == nil && value.Date != nil
+	case CustomFieldTypeSelect:
+		if value.Text != nil && value.Number == nil && value.Date == nil {
+			for _, option := range f.Options {
+				if option == *value.Text {
+					ok = true
+					break
+				}
+			}
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("%w for %s field %s", ErrInvalidCustomFieldValue, f.Type, f.Name)
+	}
+
+	return nil
+}
+
+type PanelCustomFields struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/panel_custom_fields/schema.sql
+	panelCustomFieldsSchema string
+)
+
+func newPanelCustomFields(db *Pool) *PanelCustomFields {
+	return &PanelCustomFields{
+		db,
+	}
+}
+
+func (PanelCustomFields) Schema() string {
+	return panelCustomFieldsSchema
+}
+
+func (f *PanelCustomFields) Get(ctx context.Context, fieldId int) (field PanelCustomField, err error) {
+	query := `
+SELECT "id", "panel_id", "name", "type", "options", "required", "position"
+FROM panel_custom_fields
+WHERE "id" = $1;`
+
+	err = f.QueryRow(ctx, query, fieldId).Scan(field.fieldPtrs()...)
+	return
+}
+
+func (f *PanelCustomFields) GetByPanel(ctx context.Context, panelId int) ([]PanelCustomField, error) {
+	query := `
+SELECT "id", "panel_id", "name", "type", "options", "required", "position"
+FROM panel_custom_fields
+WHERE "panel_id" = $1
+ORDER BY "position", "id";`
+
+	rows, err := f.Query(ctx, query, panelId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	fields := make([]PanelCustomField, 0)
+	for rows.Next() {
+		var field PanelCustomField
+		if err := rows.Scan(field.fieldPtrs()...); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, rows.Err()
+}
+
+func (f *PanelCustomFields) Create(ctx context.Context, field PanelCustomField) (id int, err error) {
+	query := `
+INSERT INTO panel_custom_fields("panel_id", "name", "type", "options", "required", "position")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING "id";`
+
+	err = f.QueryRow(ctx, query, field.PanelId, field.Name, field.Type, nonNilStrings(field.Options), field.Required, field.Position).Scan(&id)
+	return
+}
+
+// Update changes the field's definition. The type of the field cannot be changed, as existing values would no longer
+// be valid.
+func (f *PanelCustomFields) Update(ctx context.Context, field PanelCustomField) (err error) {
+	query := `
+UPDATE panel_custom_fields
+SET "name" = $3, "options" = $4, "required" = $5, "position" = $6
+WHERE "id" = $1 AND "panel_id" = $2;`
+
+	_, err = f.Exec(ctx, query, field.Id, field.PanelId, field.Name, nonNilStrings(field.Options), field.Required, field.Position)
+	return
+}
+
+func (f *PanelCustomFields) Delete(ctx context.Context, panelId, fieldId int) (err error) {
+	_, err = f.Exec(ctx, `DELETE FROM panel_custom_fields WHERE "id" = $1 AND "panel_id" = $2;`, fieldId, panelId)
+	return
+}
+
+func getPanelCustomFieldForTicket(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId, fieldId int) (field PanelCustomField, err error) {
+	query := `
+SELECT f."id", f."panel_id", f."name", f."type", f."options", f."required", f."position"
+FROM panel_custom_fields AS f
+INNER JOIN tickets ON tickets.panel_id = f.panel_id
+WHERE f."id" = $1 AND tickets.guild_id = $2 AND tickets.id = $3;`
+
+	err = tx.QueryRow(ctx, query, fieldId, guildId, ticketId).Scan(field.fieldPtrs()...)
+	return
+}
+
+func (f *PanelCustomField) fieldPtrs() []interface{} {
+	return []interface{}{
+		&f.Id,
+		&f.PanelId,
+		&f.Name,
+		&f.Type,
+		&f.Options,
+		&f.Required,
+		&f.Position,
+	}
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
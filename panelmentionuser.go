@@ -2,15 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type PanelUserMention struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPanelUserMention(db *pgxpool.Pool) *PanelUserMention {
+func newPanelUserMention(db *Pool) *PanelUserMention {
 	return &PanelUserMention{
 		db,
 	}
@@ -30,7 +30,7 @@ CREATE TABLE IF NOT EXISTS panel_user_mentions(
 func (p *PanelUserMention) ShouldMentionUser(ctx context.Context, panelId int) (shouldMention bool, e error) {
 	query := `SELECT "should_mention_user" from panel_user_mentions WHERE "panel_id"=$1;`
 
-	if err := p.QueryRow(ctx, query, panelId).Scan(&shouldMention); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, query, panelId).Scan(&shouldMention); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -3,14 +3,13 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type PanelRoleMentions struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPanelRoleMentions(db *pgxpool.Pool) *PanelRoleMentions {
+func newPanelRoleMentions(db *Pool) *PanelRoleMentions {
 	return &PanelRoleMentions{
 		db,
 	}
@@ -32,12 +31,13 @@ func (p *PanelRoleMentions) GetRoles(ctx context.Context, panelId int) (roles []
 	query := `SELECT "role_id" from panel_role_mentions WHERE "panel_id"=$1;`
 
 	rows, err := p.Query(ctx, query, panelId)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var roleId uint64
 		if err := rows.Scan(&roleId); err != nil {
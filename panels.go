@@ -2,9 +2,9 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 // ALTER TABLE panels ADD COLUMN default_team bool NOT NULL DEFAULT 't';
@@ -40,10 +40,10 @@ type PanelWithWelcomeMessage struct {
 }
 
 type PanelTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPanelTable(db *pgxpool.Pool) *PanelTable {
+func newPanelTable(db *Pool) *PanelTable {
 	return &PanelTable{
 		db,
 	}
@@ -119,7 +119,7 @@ WHERE "message_id" = $1;
 `
 
 	if err := p.QueryRow(ctx, query, messageId).
-		Scan(panel.fieldPtrs()...); err != nil && err != pgx.ErrNoRows {
+		Scan(panel.fieldPtrs()...); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -157,7 +157,7 @@ WHERE "panel_id" = $1;
 `
 
 	if err := p.QueryRow(ctx, query, panelId).
-		Scan(panel.fieldPtrs()...); err != nil && err != pgx.ErrNoRows {
+		Scan(panel.fieldPtrs()...); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -197,7 +197,7 @@ WHERE "guild_id" = $1 AND "custom_id" = $2;
 	switch err := p.QueryRow(ctx, query, guildId, customId).Scan(panel.fieldPtrs()...); err {
 	case nil:
 		ok = true
-	case pgx.ErrNoRows:
+	case ErrNotFound:
 	default:
 		e = err
 	}
@@ -238,7 +238,7 @@ WHERE "guild_id" = $1 AND "form_id" = $2;
 	switch err := p.QueryRow(ctx, query, guildId, formId).Scan(panel.fieldPtrs()...); err {
 	case nil:
 		ok = true
-	case pgx.ErrNoRows:
+	case ErrNotFound:
 	default:
 		e = err
 	}
@@ -281,7 +281,7 @@ WHERE forms.guild_id = $1 AND forms.form_id = $2;
 	switch err := p.QueryRow(ctx, query, guildId, customId).Scan(panel.fieldPtrs()...); err {
 	case nil:
 		ok = true
-	case pgx.ErrNoRows:
+	case ErrNotFound:
 	default:
 		e = err
 	}
@@ -320,11 +320,12 @@ WHERE "guild_id" = $1
 ORDER BY "panel_id" ASC;`
 
 	rows, err := p.Query(ctx, query, guildId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var panel Panel
 		if err := rows.Scan(panel.fieldPtrs()...); err != nil {
@@ -384,11 +385,12 @@ WHERE panels.guild_id = $1
 ORDER BY panels.panel_id ASC;`
 
 	rows, err := p.Query(ctx, query, guildId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var panel Panel
 		var embed CustomEmbed
@@ -3,14 +3,13 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type PanelTeamsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPanelTeamsTable(db *pgxpool.Pool) *PanelTeamsTable {
+func newPanelTeamsTable(db *Pool) *PanelTeamsTable {
 	return &PanelTeamsTable{
 		db,
 	}
@@ -39,11 +38,12 @@ WHERE panel_teams.panel_id = $1;
 `
 
 	rows, err := p.Query(ctx, query, panelId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var team SupportTeam
 		if err := rows.Scan(&team.Id, &team.GuildId, &team.Name, &team.OnCallRole); err != nil {
@@ -60,11 +60,12 @@ func (p *PanelTeamsTable) GetTeamIds(ctx context.Context, panelId int) (teamIds
 	query := `SELECT "team_id" FROM panel_teams WHERE "panel_id" = $1;`
 
 	rows, err := p.Query(ctx, query, panelId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var id int
 		if err := rows.Scan(&id); err != nil {
@@ -3,12 +3,11 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type ParticipantTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type Participant struct {
@@ -17,7 +16,7 @@ type Participant struct {
 	UserId   uint64
 }
 
-func newParticipantTable(db *pgxpool.Pool) *ParticipantTable {
+func newParticipantTable(db *Pool) *ParticipantTable {
 	return &ParticipantTable{
 		db,
 	}
@@ -187,11 +186,12 @@ UNION
 `
 
 	rows, err := p.Query(ctx, query, userId)
-	defer rows.Close()
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var participants []Participant
 	for rows.Next() {
 		participant := Participant{
@@ -6,14 +6,13 @@ import (
 	"github.com/TicketsBot/common/model"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type PatreonEntitlements struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPatreonEntitlements(db *pgxpool.Pool) *PatreonEntitlements {
+func newPatreonEntitlements(db *Pool) *PatreonEntitlements {
 	return &PatreonEntitlements{
 		db,
 	}
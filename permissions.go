@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type Permissions struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPermissions(db *pgxpool.Pool) *Permissions {
+func newPermissions(db *Pool) *Permissions {
 	return &Permissions{
 		db,
 	}
@@ -32,7 +31,7 @@ CREATE INDEX IF NOT EXISTS permissions_guild_id ON permissions("guild_id");
 func (p *Permissions) IsSupport(ctx context.Context, guildId, userId uint64) (support bool, e error) {
 	var admin bool
 
-	if err := p.QueryRow(ctx, `SELECT "support", "admin" from permissions WHERE "guild_id" = $1 AND "user_id" = $2;`, guildId, userId).Scan(&support, &admin); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "support", "admin" from permissions WHERE "guild_id" = $1 AND "user_id" = $2;`, guildId, userId).Scan(&support, &admin); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -44,7 +43,7 @@ func (p *Permissions) IsSupport(ctx context.Context, guildId, userId uint64) (su
 }
 
 func (p *Permissions) IsAdmin(ctx context.Context, guildId, userId uint64) (admin bool, e error) {
-	if err := p.QueryRow(ctx, `SELECT "admin" from permissions WHERE "guild_id" = $1 AND "user_id" = $2;`, guildId, userId).Scan(&admin); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "admin" from permissions WHERE "guild_id" = $1 AND "user_id" = $2;`, guildId, userId).Scan(&admin); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -53,11 +52,12 @@ func (p *Permissions) IsAdmin(ctx context.Context, guildId, userId uint64) (admi
 
 func (p *Permissions) GetAdmins(ctx context.Context, guildId uint64) (admins []uint64, e error) {
 	rows, err := p.Query(ctx, `SELECT "user_id" from permissions WHERE "guild_id" = $1 AND "admin" = true;`, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userId uint64
 		if err := rows.Scan(&userId); err != nil {
@@ -73,11 +73,12 @@ func (p *Permissions) GetAdmins(ctx context.Context, guildId uint64) (admins []u
 
 func (p *Permissions) GetSupport(ctx context.Context, guildId uint64) (support []uint64, e error) {
 	rows, err := p.Query(ctx, `SELECT "user_id" from permissions WHERE "guild_id" = $1 AND ("admin" = true OR "support" = true);`, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userId uint64
 		if err := rows.Scan(&userId); err != nil {
@@ -94,11 +95,12 @@ func (p *Permissions) GetSupport(ctx context.Context, guildId uint64) (support [
 func (p *Permissions) GetSupportOnly(ctx context.Context, guildId uint64) (support []uint64, e error) {
 	query := `SELECT "user_id" from permissions WHERE "guild_id" = $1 AND "admin" = false AND "support" = true;`
 	rows, err := p.Query(ctx, query, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userId uint64
 		if err := rows.Scan(&userId); err != nil {
@@ -0,0 +1,382 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Pool wraps pgxpool.Pool, translating errors returned by every query, batch and transaction with translateError, and
+// passing them through the QueryTracer installed with WithTracer, if any.
+type Pool struct {
+	*pgxpool.Pool
+	tracer QueryTracer
+}
+
+func newPool(pool *pgxpool.Pool, opts ...Option) *Pool {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Pool{
+		Pool:   pool,
+		tracer: o.tracer(),
+	}
+}
+
+func (p *Pool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return execTraced(ctx, startTrace(ctx, p.tracer, QueryKindExec, sql, false), p.Pool.Exec, sql, args...)
+}
+
+func (p *Pool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return queryTraced(ctx, startTrace(ctx, p.tracer, QueryKindQuery, sql, false), p.Pool.Query, sql, args...)
+}
+
+func (p *Pool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := queryTraced(ctx, startTrace(ctx, p.tracer, QueryKindQueryRow, sql, false), p.Pool.Query, sql, args...)
+	return newTracedRow(rows, err)
+}
+
+func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &tracedBatchResults{
+		trace:        startTrace(ctx, p.tracer, QueryKindBatch, "", false),
+		BatchResults: p.Pool.SendBatch(ctx, b),
+	}
+}
+
+func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	return p.BeginTx(ctx, pgx.TxOptions{})
+}
+
+func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	trace := startTrace(ctx, p.tracer, QueryKindTransaction, "", false)
+
+	tx, err := p.Pool.BeginTx(ctx, txOptions)
+	if err != nil {
+		trace.end(0, err)
+		return nil, translateError(err)
+	}
+
+	return &tracedTx{
+		Tx:     tx,
+		tracer: p.tracer,
+		trace:  trace,
+	}, nil
+}
+
+// queryTrace holds the state of a single traced operation. A nil *queryTrace is valid, and is used when no tracer is
+// installed, so that the caller lookup is skipped.
+type queryTrace struct {
+	tracer    QueryTracer
+	info      QueryInfo
+	ctx       context.Context
+	startedAt time.Time
+	finished  bool
+}
+
+func startTrace(ctx context.Context, tracer QueryTracer, kind QueryKind, sql string, inTransaction bool) *queryTrace {
+	if tracer == nil {
+		return nil
+	}
+
+	info := newQueryInfo(kind, sql, inTransaction)
+
+	return &queryTrace{
+		tracer:    tracer,
+		info:      info,
+		ctx:       tracer.TraceQueryStart(ctx, info),
+		startedAt: time.Now(),
+	}
+}
+
+// end reports the untranslated error to the tracer, so that ErrorClass sees the original SQLSTATE
+func (t *queryTrace) end(rowsAffected int64, err error) {
+	if t == nil || t.finished {
+		return
+	}
+
+	t.finished = true
+	t.tracer.TraceQueryEnd(t.ctx, t.info, QueryResult{
+		Duration:     time.Since(t.startedAt),
+		RowsAffected: rowsAffected,
+		Err:          err,
+		ErrorClass:   ErrorClass(err),
+	})
+}
+
+// tracedTx traces the lifetime of the transaction, from Begin to Commit or Rollback, as well as each query executed
+// within it.
+type tracedTx struct {
+	pgx.Tx
+	tracer QueryTracer
+	trace  *queryTrace
+}
+
+func (t *tracedTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return execTraced(ctx, startTrace(ctx, t.tracer, QueryKindExec, sql, true), t.Tx.Exec, sql, args...)
+}
+
+func (t *tracedTx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return queryTraced(ctx, startTrace(ctx, t.tracer, QueryKindQuery, sql, true), t.Tx.Query, sql, args...)
+}
+
+func (t *tracedTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := queryTraced(ctx, startTrace(ctx, t.tracer, QueryKindQueryRow, sql, true), t.Tx.Query, sql, args...)
+	return newTracedRow(rows, err)
+}
+
+func (t *tracedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return &tracedBatchResults{
+		trace:        startTrace(ctx, t.tracer, QueryKindBatch, "", true),
+		BatchResults: t.Tx.SendBatch(ctx, b),
+	}
+}
+
+func (t *tracedTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	t.trace.end(0, err)
+	return translateError(err)
+}
+
+func (t *tracedTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+
+	// Rollback is usually deferred, and so is called after a successful commit
+	if err == pgx.ErrTxClosed {
+		return err
+	}
+
+	t.trace.end(0, err)
+	return translateError(err)
+}
+
+// tracedRows ends the trace when the rows are closed, which pgx does automatically once Next returns false
+type tracedRows struct {
+	pgx.Rows
+	trace *queryTrace
+}
+
+func (r *tracedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+
+	r.finish()
+	return false
+}
+
+func (r *tracedRows) Scan(dest ...interface{}) error {
+	return translateError(r.Rows.Scan(dest...))
+}
+
+func (r *tracedRows) Err() error {
+	return translateError(r.Rows.Err())
+}
+
+func (r *tracedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *tracedRows) finish() {
+	r.trace.end(r.Rows.CommandTag().RowsAffected(), r.Rows.Err())
+}
+
+// tracedRow mirrors the behaviour of the pgx row returned by QueryRow, but is built on top of a traced Query call
+type tracedRow struct {
+	rows *tracedRows
+	err  error
+}
+
+func newTracedRow(rows pgx.Rows, err error) *tracedRow {
+	if err != nil {
+		return &tracedRow{err: err}
+	}
+
+	return &tracedRow{rows: rows.(*tracedRows)}
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	defer r.rows.Close()
+
+	// Advance the underlying rows directly, so that an empty result can be reported to the tracer as pgx.ErrNoRows
+	// before tracedRows ends the trace without an error
+	if !r.rows.Rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+
+		r.rows.trace.end(0, pgx.ErrNoRows)
+		return ErrNotFound
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	r.rows.Close()
+	return r.rows.Err()
+}
+
+type tracedBatchResults struct {
+	pgx.BatchResults
+	trace *queryTrace
+}
+
+func (b *tracedBatchResults) Exec() (pgconn.CommandTag, error) {
+	tag, err := b.BatchResults.Exec()
+	return tag, translateError(err)
+}
+
+func (b *tracedBatchResults) Query() (pgx.Rows, error) {
+	rows, err := b.BatchResults.Query()
+	if err != nil {
+		return rows, translateError(err)
+	}
+
+	// The batch as a whole is traced, rather than each query within it
+	return &tracedRows{Rows: rows}, nil
+}
+
+func (b *tracedBatchResults) QueryRow() pgx.Row {
+	return &batchRow{b.BatchResults.QueryRow()}
+}
+
+func (b *tracedBatchResults) Close() error {
+	err := b.BatchResults.Close()
+	b.trace.end(0, err)
+	return translateError(err)
+}
+
+type batchRow struct {
+	pgx.Row
+}
+
+func (r *batchRow) Scan(dest ...interface{}) error {
+	return translateError(r.Row.Scan(dest...))
+}
+
+func execTraced(
+	ctx context.Context,
+	trace *queryTrace,
+	exec func(context.Context, string, ...interface{}) (pgconn.CommandTag, error),
+	sql string,
+	args ...interface{},
+) (pgconn.CommandTag, error) {
+	tag, err := exec(ctx, sql, args...)
+	trace.end(tag.RowsAffected(), err)
+
+	return tag, translateError(err)
+}
+
+func queryTraced(
+	ctx context.Context,
+	trace *queryTrace,
+	query func(context.Context, string, ...interface{}) (pgx.Rows, error),
+	sql string,
+	args ...interface{},
+) (pgx.Rows, error) {
+	rows, err := query(ctx, sql, args...)
+	if err != nil {
+		trace.end(0, err)
+		return rows, translateError(err)
+	}
+
+	return &tracedRows{
+		Rows:  rows,
+		trace: trace,
+	}, nil
+}
+
+var packagePath = reflect.TypeOf(Pool{}).PkgPath()
+
+// wrapperTypes are skipped when looking for the method that issued a query
+var wrapperTypes = []string{
+	"(*Pool).", "(*tracedTx).", "(*tracedRow).", "(*tracedRows).", "(*tracedBatchResults).", "(*batchRow).",
+}
+
+// wrapperFunctions are skipped when looking for the method that issued a query
+var wrapperFunctions = []string{"startTrace", "newQueryInfo", "execTraced", "queryTraced"}
+
+func newQueryInfo(kind QueryKind, sql string, inTransaction bool) QueryInfo {
+	table, method := callerMethod()
+
+	return QueryInfo{
+		Kind:          kind,
+		Table:         table,
+		Method:        method,
+		SQL:           sql,
+		InTransaction: inTransaction,
+	}
+}
+
+// callerMethod finds the first method outside of the tracing wrappers on the call stack, returning its receiver type
+// and name, e.g. ("TicketTable", "GetByChannel").
+func callerMethod() (table, method string) {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		if name, ok := strings.CutPrefix(frame.Function, packagePath+"."); ok {
+			if !isWrapperFunction(name) {
+				return splitFunctionName(name)
+			}
+		} else if frame.Function != "" {
+			// Query was issued from outside this package
+			return "", frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		}
+
+		if !more {
+			return "", ""
+		}
+	}
+}
+
+func isWrapperFunction(name string) bool {
+	for _, prefix := range wrapperTypes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	for _, function := range wrapperFunctions {
+		if name == function {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitFunctionName splits e.g. "(*TicketTable).Get.func1" into ("TicketTable", "Get")
+func splitFunctionName(name string) (string, string) {
+	if !strings.HasPrefix(name, "(") {
+		parts := strings.Split(name, ".")
+		if len(parts) >= 2 && !strings.HasPrefix(parts[1], "func") {
+			// Value receiver, e.g. "TicketTable.Schema"
+			return parts[0], parts[1]
+		}
+
+		// Package level function
+		return "", parts[0]
+	}
+
+	receiver, rest, _ := strings.Cut(name, ").")
+	receiver = strings.TrimLeft(receiver, "(*")
+
+	method, _, _ := strings.Cut(rest, ".")
+	return receiver, method
+}
@@ -2,16 +2,15 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 	"time"
 )
 
 type PremiumGuilds struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPremiumGuilds(db *pgxpool.Pool) *PremiumGuilds {
+func newPremiumGuilds(db *Pool) *PremiumGuilds {
 	return &PremiumGuilds{
 		db,
 	}
@@ -36,7 +35,7 @@ func (p *PremiumGuilds) IsPremium(ctx context.Context, guildId uint64) (bool, er
 }
 
 func (p *PremiumGuilds) GetExpiry(ctx context.Context, guildId uint64) (expiry time.Time, e error) {
-	if err := p.QueryRow(ctx, `SELECT "expiry" from premium_guilds WHERE "guild_id" = $1;`, guildId).Scan(&expiry); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "expiry" from premium_guilds WHERE "guild_id" = $1;`, guildId).Scan(&expiry); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -5,15 +5,14 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type PremiumKeys struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newPremiumKeys(db *pgxpool.Pool) *PremiumKeys {
+func newPremiumKeys(db *Pool) *PremiumKeys {
 	return &PremiumKeys{
 		db,
 	}
@@ -43,7 +42,7 @@ func (k *PremiumKeys) Delete(ctx context.Context, tx pgx.Tx, key uuid.UUID) (tim
 
 	query := `DELETE from premium_keys WHERE "key" = $1 RETURNING "length", "sku_id";`
 	if err := tx.QueryRow(ctx, query, key).Scan(&length, &skuId); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return 0, uuid.Nil, false, nil
 		}
 
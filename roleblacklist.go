@@ -3,14 +3,13 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type RoleBlacklist struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newRoleBlacklist(db *pgxpool.Pool) *RoleBlacklist {
+func newRoleBlacklist(db *Pool) *RoleBlacklist {
 	return &RoleBlacklist{
 		db,
 	}
@@ -48,12 +47,13 @@ func (b *RoleBlacklist) GetBlacklistedRoles(ctx context.Context, guildId uint64)
 	query := `SELECT "role_id" FROM role_blacklist WHERE "guild_id" = $1;`
 
 	rows, err := b.Query(ctx, query, guildId)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var roleId uint64
 		if err := rows.Scan(&roleId); err != nil {
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type RolePermissions struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newRolePermissions(db *pgxpool.Pool) *RolePermissions {
+func newRolePermissions(db *Pool) *RolePermissions {
 	return &RolePermissions{
 		db,
 	}
@@ -33,7 +32,7 @@ CREATE INDEX IF NOT EXISTS role_permissions_guild_id ON role_permissions("guild_
 func (p *RolePermissions) IsSupport(ctx context.Context, roleId uint64) (bool, error) {
 	var support, admin bool
 
-	if err := p.QueryRow(ctx, `SELECT "support", "admin" from role_permissions WHERE "role_id" = $1;`, roleId).Scan(&support, &admin); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "support", "admin" from role_permissions WHERE "role_id" = $1;`, roleId).Scan(&support, &admin); err != nil && !errors.Is(err, ErrNotFound) {
 		return false, err
 	}
 
@@ -41,7 +40,7 @@ func (p *RolePermissions) IsSupport(ctx context.Context, roleId uint64) (bool, e
 }
 
 func (p *RolePermissions) IsAdmin(ctx context.Context, roleId uint64) (admin bool, e error) {
-	if err := p.QueryRow(ctx, `SELECT "admin" from role_permissions WHERE "role_id" = $1;`, roleId).Scan(&admin); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "admin" from role_permissions WHERE "role_id" = $1;`, roleId).Scan(&admin); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -50,11 +49,12 @@ func (p *RolePermissions) IsAdmin(ctx context.Context, roleId uint64) (admin boo
 
 func (p *RolePermissions) GetAdminRoles(ctx context.Context, guildId uint64) (adminRoles []uint64, e error) {
 	rows, err := p.Query(ctx, `SELECT "role_id" from role_permissions WHERE "guild_id" = $1 AND "admin" = true;`, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var roleId uint64
 		if err := rows.Scan(&roleId); err != nil {
@@ -70,11 +70,12 @@ func (p *RolePermissions) GetAdminRoles(ctx context.Context, guildId uint64) (ad
 
 func (p *RolePermissions) GetSupportRoles(ctx context.Context, guildId uint64) (supportRoles []uint64, e error) {
 	rows, err := p.Query(ctx, `SELECT "role_id" from role_permissions WHERE "guild_id" = $1 AND ("admin" = true OR "support" = true);`, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var roleId uint64
 		if err := rows.Scan(&roleId); err != nil {
@@ -91,11 +92,12 @@ func (p *RolePermissions) GetSupportRoles(ctx context.Context, guildId uint64) (
 func (p *RolePermissions) GetSupportRolesOnly(ctx context.Context, guildId uint64) (supportRoles []uint64, e error) {
 	query := `SELECT "role_id" from role_permissions WHERE "guild_id" = $1 AND "admin" = false AND "support" = true;`
 	rows, err := p.Query(ctx, query, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
-		e = err
+	if err != nil {
+		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var roleId uint64
 		if err := rows.Scan(&roleId); err != nil {
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"github.com/TicketsBot/common/model"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type ScheduledTicketActionType string
+
+const (
+	ScheduledActionClose        ScheduledTicketActionType = "close"
+	ScheduledActionRemind       ScheduledTicketActionType = "remind"
+	ScheduledActionUnclaim      ScheduledTicketActionType = "unclaim"
+	ScheduledActionEscalate     ScheduledTicketActionType = "escalate"
+	ScheduledActionChangeStatus ScheduledTicketActionType = "change_status"
+	ScheduledActionPostMessage  ScheduledTicketActionType = "post_message"
+)
+
+type ScheduledTicketActionStatus string
+
+const (
+	ScheduledActionStatusPending   ScheduledTicketActionStatus = "pending"
+	ScheduledActionStatusRunning   ScheduledTicketActionStatus = "running"
+	ScheduledActionStatusCompleted ScheduledTicketActionStatus = "completed"
+	ScheduledActionStatusCancelled ScheduledTicketActionStatus = "cancelled"
+	ScheduledActionStatusFailed    ScheduledTicketActionStatus = "failed"
+)
+
+// ScheduledTicketActionPayload holds the parameters of the action. Which fields are used depends on the action: Reason
+// for close, UserId and Message for remind, Status for change_status, and Message or TagId for post_message.
+type ScheduledTicketActionPayload struct {
+	Reason  *string             `json:"reason,omitempty"`
+	UserId  *uint64             `json:"user_id,string,omitempty"`
+	Message *string             `json:"message,omitempty"`
+	TagId   *string             `json:"tag_id,omitempty"`
+	Status  *model.TicketStatus `json:"status,omitempty"`
+}
+
+// ScheduledTicketAction is an action to be performed on a ticket at RunAt. If IdempotencyKey is set, scheduling another
+// action with the same key in the guild returns the existing action instead. If CancelOnActivity is set, the action is
+// cancelled by CancelOnActivity, e.g. to escalate a ticket only if it goes unanswered.
+type ScheduledTicketAction struct {
+	Id               int64                        `json:"id"`
+	GuildId          uint64                       `json:"guild_id,string"`
+	TicketId         int                          `json:"ticket_id"`
+	Action           ScheduledTicketActionType    `json:"action"`
+	Payload          ScheduledTicketActionPayload `json:"payload"`
+	CreatedBy        *uint64                      `json:"created_by,string"`
+	IdempotencyKey   *string                      `json:"idempotency_key"`
+	CancelOnActivity bool                         `json:"cancel_on_activity"`
+	RunAt            time.Time                    `json:"run_at"`
+	Status           ScheduledTicketActionStatus  `json:"status"`
+	Attempts         int                          `json:"attempts"`
+	MaxAttempts      int                          `json:"max_attempts"`
+	LockedUntil      *time.Time                   `json:"locked_until"`
+	LastError        *string                      `json:"last_error"`
+	CreatedAt        time.Time                    `json:"created_at"`
+	FinishedAt       *time.Time                   `json:"finished_at"`
+}
+
+const defaultScheduledActionMaxAttempts = 5
+
+type ScheduledTicketActions struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/scheduled_ticket_actions/schema.sql
+	scheduledTicketActionsSchema string
+
+	//go:embed sql/scheduled_ticket_actions/schedule.sql
+	scheduledTicketActionsSchedule string
+
+	//go:embed sql/scheduled_ticket_actions/claim.sql
+	scheduledTicketActionsClaim string
+)
+
+const scheduledTicketActionColumns = `id, guild_id, ticket_id, action, payload, created_by, idempotency_key, cancel_on_activity, run_at, status, attempts, max_attempts, locked_until, last_error, created_at, finished_at`
+
+func newScheduledTicketActions(db *Pool) *ScheduledTicketActions {
+	return &ScheduledTicketActions{
+		db,
+	}
+}
+
+func (ScheduledTicketActions) Schema() string {
+	return scheduledTicketActionsSchema
+}
+
+// Schedule adds the action to the queue, returning its ID. If an action with the same idempotency key already exists
+// in the guild, its ID is returned instead and created is false, even if it was created concurrently. The existing
+// action is left unchanged. A MaxAttempts of 0 uses the default of 5.
+func (a *ScheduledTicketActions) Schedule(ctx context.Context, action ScheduledTicketAction) (id int64, created bool, err error) {
+	payload, err := json.MarshalToString(action.Payload)
+	if err != nil {
+		return 0, false, err
+	}
+
+	maxAttempts := action.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = defaultScheduledActionMaxAttempts
+	}
+
+	err = a.QueryRow(ctx, scheduledTicketActionsSchedule,
+		action.GuildId,
+		action.TicketId,
+		action.Action,
+		payload,
+		action.CreatedBy,
+		action.IdempotencyKey,
+		action.CancelOnActivity,
+		action.RunAt,
+		maxAttempts,
+	).Scan(&id, &created)
+	return
+}
+
+func (a *ScheduledTicketActions) Get(ctx context.Context, guildId uint64, id int64) (ScheduledTicketAction, error) {
+	query := `SELECT ` + scheduledTicketActionColumns + ` FROM scheduled_ticket_actions WHERE "guild_id" = $1 AND "id" = $2;`
+
+	var action ScheduledTicketAction
+	var payload string
+	if err := a.QueryRow(ctx, query, guildId, id).Scan(action.fieldPtrs(&payload)...); err != nil {
+		return ScheduledTicketAction{}, err
+	}
+
+	if err := json.UnmarshalFromString(payload, &action.Payload); err != nil {
+		return ScheduledTicketAction{}, err
+	}
+
+	return action, nil
+}
+
+// GetPending returns the actions waiting to run on the ticket, ordered by when they are due
+func (a *ScheduledTicketActions) GetPending(ctx context.Context, guildId uint64, ticketId int) ([]ScheduledTicketAction, error) {
+	query := `
+SELECT ` + scheduledTicketActionColumns + `
+FROM scheduled_ticket_actions
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "status" IN ('pending', 'running')
+ORDER BY "run_at";`
+
+	rows, err := a.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanScheduledTicketActions(rows)
+}
+
+// Claim marks up to limit due actions as running and returns them. Rows locked by other workers are skipped, so
+// several workers can poll concurrently. Each claim holds a lease of leaseDuration: if the action is neither completed
+// nor failed before the lease expires, it is claimed again, so actions are run at least once. Actions whose lease
+// expires on their final attempt are marked as failed. Actions on closed tickets are not claimed.
+func (a *ScheduledTicketActions) Claim(ctx context.Context, limit int, leaseDuration time.Duration) ([]ScheduledTicketAction, error) {
+	rows, err := a.Query(ctx, scheduledTicketActionsClaim, limit, leaseDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	return scanScheduledTicketActions(rows)
+}
+
+func (a *ScheduledTicketActions) Complete(ctx context.Context, id int64) (err error) {
+	query := `
+UPDATE scheduled_ticket_actions
+SET "status" = 'completed', "locked_until" = NULL, "finished_at" = NOW()
+WHERE "id" = $1 AND "status" = 'running';`
+
+	_, err = a.Exec(ctx, query, id)
+	return
+}
+
+// Fail records the error, and schedules the action to be retried after retryDelay. If the action has used all of its
+// attempts, it is marked as failed instead.
+func (a *ScheduledTicketActions) Fail(ctx context.Context, id int64, reason string, retryDelay time.Duration) (err error) {
+	query := `
+UPDATE scheduled_ticket_actions
+SET "status" = CASE WHEN "attempts" >= "max_attempts" THEN 'failed' ELSE 'pending' END::scheduled_ticket_action_status,
+	"run_at" = CASE WHEN "attempts" >= "max_attempts" THEN "run_at" ELSE NOW() + $3::INTERVAL END,
+	"finished_at" = CASE WHEN "attempts" >= "max_attempts" THEN NOW() END,
+	"locked_until" = NULL,
+	"last_error" = $2
+WHERE "id" = $1 AND "status" = 'running';`
+
+	_, err = a.Exec(ctx, query, id, reason, retryDelay)
+	return
+}
+
+// Cancel returns ErrNotFound if the action does not exist, or has already run
+func (a *ScheduledTicketActions) Cancel(ctx context.Context, guildId uint64, id int64) error {
+	query := `
+UPDATE scheduled_ticket_actions
+SET "status" = 'cancelled', "locked_until" = NULL, "finished_at" = NOW()
+WHERE "guild_id" = $1 AND "id" = $2 AND "status" = 'pending';`
+
+	res, err := a.Exec(ctx, query, guildId, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CancelForTicket cancels the pending actions on the ticket. If no action types are given, all pending actions are
+// cancelled.
+func (a *ScheduledTicketActions) CancelForTicket(ctx context.Context, guildId uint64, ticketId int, actions ...ScheduledTicketActionType) (cancelled int64, err error) {
+	query := `
+UPDATE scheduled_ticket_actions
+SET "status" = 'cancelled', "finished_at" = NOW()
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "status" = 'pending' AND (cardinality($3::text[]) = 0 OR "action"::text = ANY($3::text[]));`
+
+	types := make([]string, len(actions))
+	for i, action := range actions {
+		types[i] = string(action)
+	}
+
+	res, err := a.Exec(ctx, query, guildId, ticketId, types)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
+
+// CancelOnActivity cancels the pending actions on the ticket that were scheduled with CancelOnActivity set. It should
+// be called when a message is sent in the ticket.
+func (a *ScheduledTicketActions) CancelOnActivity(ctx context.Context, guildId uint64, ticketId int) (err error) {
+	query := `
+UPDATE scheduled_ticket_actions
+SET "status" = 'cancelled', "finished_at" = NOW()
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "status" = 'pending' AND "cancel_on_activity";`
+
+	_, err = a.Exec(ctx, query, guildId, ticketId)
+	return
+}
+
+// Cleanup cancels the pending actions on closed tickets, and deletes finished actions older than retention
+func (a *ScheduledTicketActions) Cleanup(ctx context.Context, retention time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	cancelQuery := `
+UPDATE scheduled_ticket_actions
+SET "status" = 'cancelled', "finished_at" = NOW()
+FROM tickets
+WHERE scheduled_ticket_actions.guild_id = tickets.guild_id
+	AND scheduled_ticket_actions.ticket_id = tickets.id
+	AND NOT tickets.open
+	AND scheduled_ticket_actions.status = 'pending';`
+
+	if _, err := tx.Exec(ctx, cancelQuery); err != nil {
+		return err
+	}
+
+	deleteQuery := `
+DELETE FROM scheduled_ticket_actions
+WHERE "status" IN ('completed', 'cancelled', 'failed') AND "finished_at" < NOW() - $1::INTERVAL;`
+
+	if _, err := tx.Exec(ctx, deleteQuery, retention); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func scanScheduledTicketActions(rows pgx.Rows) ([]ScheduledTicketAction, error) {
+	actions := make([]ScheduledTicketAction, 0)
+	for rows.Next() {
+		var action ScheduledTicketAction
+		var payload string
+		if err := rows.Scan(action.fieldPtrs(&payload)...); err != nil {
+			return nil, err
+		}
+
+		if err := json.UnmarshalFromString(payload, &action.Payload); err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
+
+func (a *ScheduledTicketAction) fieldPtrs(payload *string) []interface{} {
+	return []interface{}{
+		&a.Id,
+		&a.GuildId,
+		&a.TicketId,
+		&a.Action,
+		payload,
+		&a.CreatedBy,
+		&a.IdempotencyKey,
+		&a.CancelOnActivity,
+		&a.RunAt,
+		&a.Status,
+		&a.Attempts,
+		&a.MaxAttempts,
+		&a.LockedUntil,
+		&a.LastError,
+		&a.CreatedAt,
+		&a.FinishedAt,
+	}
+}
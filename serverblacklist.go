@@ -3,15 +3,13 @@ package database
 import (
 	"context"
 	"errors"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type ServerBlacklist struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newServerBlacklist(db *pgxpool.Pool) *ServerBlacklist {
+func newServerBlacklist(db *Pool) *ServerBlacklist {
 	return &ServerBlacklist{
 		db,
 	}
@@ -26,7 +24,7 @@ func (b *ServerBlacklist) IsBlacklisted(ctx context.Context, guildId uint64) (bo
 
 	var reason *string
 	if err := b.QueryRow(ctx, query, guildId).Scan(&reason); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil, nil
 		} else {
 			return false, nil, err
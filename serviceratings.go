@@ -2,16 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type ServiceRatings struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newServiceRatings(db *pgxpool.Pool) *ServiceRatings {
+func newServiceRatings(db *Pool) *ServiceRatings {
 	return &ServiceRatings{
 		db,
 	}
@@ -34,7 +33,7 @@ func (r *ServiceRatings) Get(ctx context.Context, guildId uint64, ticketId int)
 	err := r.QueryRow(ctx, query, guildId, ticketId).Scan(&rating)
 	if err == nil {
 		return rating, true, nil
-	} else if err == pgx.ErrNoRows {
+	} else if errors.Is(err, ErrNotFound) {
 		return 0, false, nil
 	} else {
 		return 0, false, err
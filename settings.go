@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 // TODO: Migrate all settings to this table
@@ -41,10 +40,10 @@ func defaultSettings() Settings {
 }
 
 type SettingsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newSettingsTable(db *pgxpool.Pool) *SettingsTable {
+func newSettingsTable(db *Pool) *SettingsTable {
 	return &SettingsTable{
 		db,
 	}
@@ -112,7 +111,7 @@ WHERE "guild_id" = $1;
 
 	if err == nil {
 		return settings, nil
-	} else if err == pgx.ErrNoRows {
+	} else if errors.Is(err, ErrNotFound) {
 		return defaultSettings(), nil
 	} else {
 		return settings, err
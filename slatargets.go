@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SlaBreach is an open ticket that has not received a response from staff within its first response target
+type SlaBreach struct {
+	GuildId   uint64        `json:"guild_id,string"`
+	TicketId  int           `json:"ticket_id"`
+	ChannelId *uint64       `json:"channel_id,string"`
+	PanelId   *int          `json:"panel_id"`
+	OpenTime  time.Time     `json:"open_time"`
+	Target    time.Duration `json:"target"`
+	Overdue   time.Duration `json:"overdue"`
+}
+
+// SlaTargets stores the first response targets of each guild. A row with no panel ID is the guild's default, which
+// applies to tickets opened from panels without their own target.
+type SlaTargets struct {
+	*Pool
+}
+
+func newSlaTargets(db *Pool) *SlaTargets {
+	return &SlaTargets{
+		db,
+	}
+}
+
+func (SlaTargets) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS sla_targets(
+	"guild_id" int8 NOT NULL,
+	"panel_id" int DEFAULT NULL,
+	"first_response" interval NOT NULL,
+	CONSTRAINT sla_targets_first_response_positive CHECK ("first_response" > INTERVAL '0'),
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE ON UPDATE CASCADE,
+	UNIQUE NULLS NOT DISTINCT("guild_id", "panel_id")
+);
+`
+}
+
+// slaTargetJoin joins the first response target of each ticket, which is selected as effective_target
+const slaTargetJoin = `
+LEFT JOIN sla_targets panel_target
+	ON panel_target.guild_id = tickets.guild_id AND panel_target.panel_id = tickets.panel_id
+LEFT JOIN sla_targets guild_target
+	ON guild_target.guild_id = tickets.guild_id AND guild_target.panel_id IS NULL
+CROSS JOIN LATERAL (SELECT COALESCE(panel_target.first_response, guild_target.first_response) AS effective_target) target`
+
+// GetGuildDefault returns nil if the guild has no default target
+func (s *SlaTargets) GetGuildDefault(ctx context.Context, guildId uint64) (target *time.Duration, e error) {
+	query := `SELECT "first_response" FROM sla_targets WHERE "guild_id" = $1 AND "panel_id" IS NULL;`
+	if err := s.QueryRow(ctx, query, guildId).Scan(&target); err != nil && !errors.Is(err, ErrNotFound) {
+		e = err
+	}
+
+	return
+}
+
+// GetForPanel returns the target that applies to tickets opened from the panel, falling back to the guild's default
+func (s *SlaTargets) GetForPanel(ctx context.Context, guildId uint64, panelId int) (target *time.Duration, e error) {
+	query := `
+SELECT "first_response"
+FROM sla_targets
+WHERE "guild_id" = $1 AND ("panel_id" = $2 OR "panel_id" IS NULL)
+ORDER BY "panel_id" NULLS LAST
+LIMIT 1;`
+
+	if err := s.QueryRow(ctx, query, guildId, panelId).Scan(&target); err != nil && !errors.Is(err, ErrNotFound) {
+		e = err
+	}
+
+	return
+}
+
+// GetPanelOverrides returns the targets set on individual panels, keyed by panel ID
+func (s *SlaTargets) GetPanelOverrides(ctx context.Context, guildId uint64) (map[int]time.Duration, error) {
+	query := `SELECT "panel_id", "first_response" FROM sla_targets WHERE "guild_id" = $1 AND "panel_id" IS NOT NULL;`
+
+	rows, err := s.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	targets := make(map[int]time.Duration)
+	for rows.Next() {
+		var panelId int
+		var target time.Duration
+		if err := rows.Scan(&panelId, &target); err != nil {
+			return nil, err
+		}
+
+		targets[panelId] = target
+	}
+
+	return targets, rows.Err()
+}
+
+// Set sets the target of the panel, or the guild's default if panelId is nil
+func (s *SlaTargets) Set(ctx context.Context, guildId uint64, panelId *int, firstResponse time.Duration) (err error) {
+	query := `
+INSERT INTO sla_targets("guild_id", "panel_id", "first_response")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id", "panel_id") DO UPDATE SET "first_response" = $3;`
+
+	_, err = s.Exec(ctx, query, guildId, panelId, firstResponse)
+	return
+}
+
+// Delete removes the target of the panel, or the guild's default if panelId is nil
+func (s *SlaTargets) Delete(ctx context.Context, guildId uint64, panelId *int) (err error) {
+	query := `DELETE FROM sla_targets WHERE "guild_id" = $1 AND "panel_id" IS NOT DISTINCT FROM $2;`
+	_, err = s.Exec(ctx, query, guildId, panelId)
+	return
+}
+
+// GetBreaches returns the open tickets in the guild that are past their first response target without a response,
+// most overdue first
+func (s *SlaTargets) GetBreaches(ctx context.Context, guildId uint64) ([]SlaBreach, error) {
+	query := `
+SELECT tickets.guild_id, tickets.id, tickets.channel_id, tickets.panel_id, tickets.open_time, target.effective_target, NOW() - tickets.open_time - target.effective_target
+FROM tickets` + slaTargetJoin + `
+LEFT JOIN first_response_time
+	ON first_response_time.guild_id = tickets.guild_id AND first_response_time.ticket_id = tickets.id
+WHERE tickets.guild_id = $1
+	AND tickets.open
+	AND first_response_time.ticket_id IS NULL
+	AND tickets.open_time < NOW() - target.effective_target
+ORDER BY tickets.open_time + target.effective_target;`
+
+	rows, err := s.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	breaches := make([]SlaBreach, 0)
+	for rows.Next() {
+		var breach SlaBreach
+		if err := rows.Scan(
+			&breach.GuildId,
+			&breach.TicketId,
+			&breach.ChannelId,
+			&breach.PanelId,
+			&breach.OpenTime,
+			&breach.Target,
+			&breach.Overdue,
+		); err != nil {
+			return nil, err
+		}
+
+		breaches = append(breaches, breach)
+	}
+
+	return breaches, rows.Err()
+}
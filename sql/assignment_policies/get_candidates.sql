@@ -0,0 +1,36 @@
+WITH members AS (
+    SELECT support_team_members.user_id
+    FROM support_team_members
+    INNER JOIN support_team ON support_team.id = support_team_members.team_id
+    WHERE support_team.guild_id = $1 AND (
+        support_team_members.team_id = $2
+        OR
+        support_team_members.team_id IN (SELECT panel_teams.team_id FROM panel_teams WHERE panel_teams.panel_id = $3)
+    )
+
+    UNION
+
+    SELECT permissions.user_id
+    FROM permissions
+    INNER JOIN panels ON panels.guild_id = permissions.guild_id
+    WHERE permissions.guild_id = $1
+      AND panels.panel_id = $3
+      AND panels.default_team
+      AND (permissions.support OR permissions.admin)
+)
+SELECT members.user_id, COUNT(tickets.id)
+FROM members
+LEFT JOIN assignment_capacities
+    ON assignment_capacities.guild_id = $1 AND assignment_capacities.user_id = members.user_id
+LEFT JOIN on_call
+    ON on_call.guild_id = $1 AND on_call.user_id = members.user_id
+LEFT JOIN ticket_assignments
+    ON ticket_assignments.guild_id = $1 AND ticket_assignments.user_id = members.user_id AND ticket_assignments.released_at IS NULL
+LEFT JOIN tickets
+    ON tickets.guild_id = ticket_assignments.guild_id AND tickets.id = ticket_assignments.ticket_id AND tickets.open
+WHERE members.user_id != $4
+  AND (NOT $5 OR COALESCE(on_call.is_on_call, FALSE))
+GROUP BY members.user_id, assignment_capacities.max_open_tickets
+HAVING COALESCE(assignment_capacities.max_open_tickets, $6) IS NULL
+    OR COUNT(tickets.id) < COALESCE(assignment_capacities.max_open_tickets, $6)
+ORDER BY members.user_id;
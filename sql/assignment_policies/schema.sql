@@ -0,0 +1,24 @@
+DO $$
+BEGIN
+	CREATE TYPE assignment_strategy AS ENUM ('round_robin', 'least_open', 'random_on_call');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS assignment_policies (
+    id SERIAL NOT NULL UNIQUE,
+    guild_id INT8 NOT NULL,
+    panel_id INT DEFAULT NULL UNIQUE,
+    team_id INT DEFAULT NULL UNIQUE,
+    strategy assignment_strategy NOT NULL,
+    only_on_call BOOLEAN NOT NULL DEFAULT FALSE,
+    default_capacity INT DEFAULT NULL,
+    last_assigned_user_id INT8 DEFAULT NULL,
+    CONSTRAINT assignment_policies_one_target CHECK (num_nonnulls(panel_id, team_id) = 1),
+    CONSTRAINT assignment_policies_default_capacity_positive CHECK (default_capacity IS NULL OR default_capacity > 0),
+    FOREIGN KEY (panel_id) REFERENCES panels(panel_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES support_team(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS assignment_policies_guild_id ON assignment_policies (guild_id);
@@ -0,0 +1,31 @@
+SELECT tickets.guild_id,
+       tickets.id,
+       tickets.channel_id,
+       tickets.panel_id,
+       CASE WHEN ticket_last_message.last_message_id IS NULL THEN 'no_response' ELSE 'inactive' END
+FROM tickets
+INNER JOIN auto_close
+    ON auto_close.guild_id = tickets.guild_id
+LEFT JOIN panel_auto_close
+    ON panel_auto_close.panel_id = tickets.panel_id
+LEFT JOIN ticket_last_message
+    ON ticket_last_message.guild_id = tickets.guild_id AND ticket_last_message.ticket_id = tickets.id
+LEFT JOIN auto_close_exclude
+    ON auto_close_exclude.guild_id = tickets.guild_id AND auto_close_exclude.ticket_id = tickets.id
+WHERE tickets.open
+  AND (tickets.guild_id, tickets.id) > ($2, $3)
+  AND COALESCE(panel_auto_close.enabled, auto_close.enabled)
+  AND auto_close_exclude.guild_id IS NULL
+  AND (
+        (
+            ticket_last_message.last_message_id IS NULL AND
+            tickets.open_time < NOW() - COALESCE(panel_auto_close.since_open_with_no_response, auto_close.since_open_with_no_response)
+        )
+        OR
+        (
+            ticket_last_message.user_is_staff AND
+            ticket_last_message.last_message_time < NOW() - COALESCE(panel_auto_close.since_last_message, auto_close.since_last_message)
+        )
+    )
+ORDER BY tickets.guild_id, tickets.id
+LIMIT $1;
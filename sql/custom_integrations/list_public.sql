@@ -0,0 +1,78 @@
+WITH previous AS (
+    -- The baseline is the latest snapshot from at least 7 days ago. Integrations with less history than that use
+    -- their oldest snapshot instead, as GREATEST ties every older snapshot at CURRENT_DATE - 7.
+    SELECT DISTINCT ON (integration_id) integration_id, count
+    FROM custom_integration_guild_count_history
+    ORDER BY integration_id, GREATEST(snapshot_date, CURRENT_DATE - 7) ASC, snapshot_date DESC
+), listings AS (
+    SELECT
+        integrations.id,
+        integrations.owner_id,
+        integrations.webhook_url,
+        integrations.validation_url,
+        integrations.http_method,
+        integrations.name,
+        integrations.description,
+        integrations.image_url,
+        integrations.privacy_policy_url,
+        integrations.public,
+        integrations.approved,
+        integrations.created_at,
+        integrations.category,
+        COALESCE(counts.count, 0) AS guild_count,
+        -- Without any snapshot there is nothing to compare against, so the delta is 0
+        COALESCE(counts.count, 0) - COALESCE(previous.count, counts.count, 0) AS install_delta,
+        COALESCE(
+            (SELECT array_agg(tags.tag ORDER BY tags.tag) FROM custom_integration_tags AS tags WHERE tags.integration_id = integrations.id),
+            '{}'
+        ) AS tags
+    FROM custom_integrations AS integrations
+    LEFT OUTER JOIN custom_integration_guild_counts counts ON integrations.id = counts.integration_id
+    LEFT OUTER JOIN previous ON integrations.id = previous.integration_id
+    WHERE integrations.public = 't' AND integrations.approved = 't' AND
+        (
+            $1::text = '' OR
+            integrations.search_vector @@ websearch_to_tsquery('english', $1::text) OR
+            integrations.name ILIKE '%' || $1::text || '%'
+        ) AND
+        (
+            cardinality($2::text[]) = 0 OR
+            EXISTS(
+                SELECT 1
+                FROM custom_integration_tags AS tags
+                WHERE tags.integration_id = integrations.id AND tags.tag = ANY($2::text[])
+            )
+        ) AND
+        ($8::text = '' OR integrations.category = $8::text)
+), sorted AS (
+    SELECT
+        listings.*,
+        CASE $3::text
+            WHEN 'recent' THEN COALESCE((EXTRACT(EPOCH FROM listings.created_at) * 1000000)::int8, 0)
+            WHEN 'trending' THEN listings.install_delta::int8
+            ELSE listings.guild_count::int8
+        END AS sort_key
+    FROM listings
+)
+SELECT
+    id,
+    owner_id,
+    webhook_url,
+    validation_url,
+    http_method,
+    name,
+    description,
+    image_url,
+    privacy_policy_url,
+    public,
+    approved,
+    created_at,
+    category,
+    guild_count,
+    install_delta,
+    tags,
+    sort_key
+FROM sorted
+WHERE $4::bool = false OR (sort_key, id) < ($5::int8, $6::int4)
+ORDER BY sort_key DESC, id DESC
+LIMIT $7;
@@ -0,0 +1,11 @@
+INSERT INTO ticket_escalation_state (guild_id, ticket_id, policy_id, unanswered_since, last_position, last_escalated_at)
+VALUES ($1, $2, $3, $4, $5, NOW())
+ON CONFLICT (guild_id, ticket_id) DO UPDATE
+    SET policy_id         = EXCLUDED.policy_id,
+        unanswered_since  = EXCLUDED.unanswered_since,
+        last_position     = EXCLUDED.last_position,
+        last_escalated_at = EXCLUDED.last_escalated_at
+    WHERE ticket_escalation_state.policy_id != EXCLUDED.policy_id
+       OR ticket_escalation_state.unanswered_since != EXCLUDED.unanswered_since
+       OR ticket_escalation_state.last_position < EXCLUDED.last_position
+RETURNING TRUE;
@@ -0,0 +1,55 @@
+-- The escalation state only applies to the wait it was recorded for. A staff reply after the last escalation ends
+-- that wait, and the next customer message starts a new one with a later unanswered_since, so the policy then starts
+-- again from its first step.
+WITH waiting AS (
+    SELECT tickets.guild_id,
+           tickets.id AS ticket_id,
+           tickets.channel_id,
+           escalation_policies.id AS policy_id,
+           COALESCE(ticket_last_message.unanswered_since, tickets.open_time) AS unanswered_since,
+           ticket_escalation_state.policy_id AS state_policy_id,
+           ticket_escalation_state.unanswered_since AS state_unanswered_since,
+           ticket_escalation_state.last_position AS state_last_position
+    FROM tickets
+    INNER JOIN escalation_policies
+        ON escalation_policies.panel_id = tickets.panel_id AND escalation_policies.enabled
+    LEFT JOIN ticket_last_message
+        ON ticket_last_message.guild_id = tickets.guild_id AND ticket_last_message.ticket_id = tickets.id
+    LEFT JOIN ticket_escalation_state
+        ON ticket_escalation_state.guild_id = tickets.guild_id AND ticket_escalation_state.ticket_id = tickets.id
+    WHERE tickets.open
+      AND (ticket_last_message.last_message_id IS NULL OR NOT ticket_last_message.user_is_staff)
+), unanswered AS (
+    SELECT waiting.guild_id,
+           waiting.ticket_id,
+           waiting.channel_id,
+           waiting.policy_id,
+           waiting.unanswered_since,
+           CASE
+               WHEN waiting.state_policy_id = waiting.policy_id AND waiting.state_unanswered_since = waiting.unanswered_since
+                   THEN waiting.state_last_position
+               ELSE -1
+           END AS last_position
+    FROM waiting
+)
+SELECT unanswered.guild_id,
+       unanswered.ticket_id,
+       unanswered.channel_id,
+       unanswered.policy_id,
+       unanswered.unanswered_since,
+       next_step.position,
+       next_step.delay_minutes,
+       next_step.action,
+       next_step.team_id,
+       next_step.role_id
+FROM unanswered
+CROSS JOIN LATERAL (
+    SELECT escalation_steps.position, escalation_steps.delay_minutes, escalation_steps.action, escalation_steps.team_id, escalation_steps.role_id
+    FROM escalation_steps
+    WHERE escalation_steps.policy_id = unanswered.policy_id AND escalation_steps.position > unanswered.last_position
+    ORDER BY escalation_steps.position
+    LIMIT 1
+) next_step
+WHERE unanswered.unanswered_since + make_interval(mins => next_step.delay_minutes) <= NOW()
+ORDER BY unanswered.unanswered_since + make_interval(mins => next_step.delay_minutes)
+LIMIT $1;
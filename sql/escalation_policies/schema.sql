@@ -0,0 +1,45 @@
+DO $$
+BEGIN
+	CREATE TYPE escalation_action AS ENUM ('support_team', 'on_call_role', 'admins');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id SERIAL NOT NULL UNIQUE,
+    guild_id INT8 NOT NULL,
+    panel_id INT NOT NULL UNIQUE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    FOREIGN KEY (panel_id) REFERENCES panels(panel_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS escalation_steps (
+    policy_id INT NOT NULL,
+    position INT2 NOT NULL,
+    delay_minutes INT NOT NULL,
+    action escalation_action NOT NULL,
+    team_id INT DEFAULT NULL,
+    role_id INT8 DEFAULT NULL,
+    CONSTRAINT escalation_steps_delay_positive CHECK (delay_minutes > 0),
+    CONSTRAINT escalation_steps_target CHECK (
+        (action = 'support_team' AND team_id IS NOT NULL) OR
+        (action = 'on_call_role' AND role_id IS NOT NULL) OR
+        (action = 'admins')
+    ),
+    FOREIGN KEY (policy_id) REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES support_team(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (policy_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS ticket_escalation_state (
+    guild_id INT8 NOT NULL,
+    ticket_id INT4 NOT NULL,
+    policy_id INT NOT NULL,
+    unanswered_since TIMESTAMPTZ NOT NULL,
+    last_position INT2 NOT NULL,
+    last_escalated_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (guild_id, ticket_id) REFERENCES tickets(guild_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (policy_id) REFERENCES escalation_policies(id) ON DELETE CASCADE,
+    PRIMARY KEY (guild_id, ticket_id)
+);
@@ -0,0 +1,9 @@
+SELECT macros.id, macros.guild_id, macros.name, macros.team_id, macros.panel_id, macros.content, macros.embed, macros.usage_count, macros.last_used_at
+FROM macros
+WHERE macros.guild_id = $1
+  AND (
+    (macros.team_id IS NULL AND macros.panel_id IS NULL)
+    OR macros.panel_id = $2
+    OR macros.team_id = ANY($3::int[])
+  )
+ORDER BY macros.usage_count DESC, macros.name;
@@ -0,0 +1,10 @@
+SELECT tickets.id,
+       tickets.user_id,
+       tickets.channel_id,
+       tickets.open_time,
+       ticket_claims.user_id,
+       panels.title
+FROM tickets
+LEFT JOIN ticket_claims ON ticket_claims.guild_id = tickets.guild_id AND ticket_claims.ticket_id = tickets.id
+LEFT JOIN panels ON panels.panel_id = tickets.panel_id
+WHERE tickets.guild_id = $1 AND tickets.id = $2;
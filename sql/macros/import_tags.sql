@@ -0,0 +1,4 @@
+INSERT INTO macros (guild_id, name, content, embed)
+SELECT tags.guild_id, LOWER(tags.tag_id), tags.content, tags.embed
+FROM tags
+ON CONFLICT (guild_id, name) DO NOTHING;
@@ -0,0 +1,46 @@
+DO $$
+BEGIN
+	CREATE TYPE macro_action_type AS ENUM ('set_status', 'add_label', 'close', 'transfer_team');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS macros (
+    id SERIAL NOT NULL UNIQUE,
+    guild_id INT8 NOT NULL,
+    name VARCHAR(32) NOT NULL,
+    team_id INT DEFAULT NULL,
+    panel_id INT DEFAULT NULL,
+    content TEXT DEFAULT NULL CONSTRAINT macros_content_length CHECK (length(content) <= 4096),
+    embed JSONB DEFAULT NULL,
+    usage_count INT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMPTZ DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT macros_single_scope CHECK (team_id IS NULL OR panel_id IS NULL),
+    FOREIGN KEY (team_id) REFERENCES support_team(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (panel_id) REFERENCES panels(panel_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    UNIQUE (guild_id, name),
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS macros_guild_id ON macros (guild_id);
+
+CREATE TABLE IF NOT EXISTS macro_actions (
+    macro_id INT NOT NULL,
+    position INT2 NOT NULL,
+    action macro_action_type NOT NULL,
+    status ticket_status DEFAULT NULL,
+    label_id INT DEFAULT NULL,
+    close_reason TEXT DEFAULT NULL CONSTRAINT macro_actions_close_reason_length CHECK (length(close_reason) <= 1024),
+    team_id INT DEFAULT NULL,
+    CONSTRAINT macro_actions_parameters CHECK (
+        (action = 'set_status' AND status IS NOT NULL) OR
+        (action = 'add_label' AND label_id IS NOT NULL) OR
+        (action = 'close') OR
+        (action = 'transfer_team' AND team_id IS NOT NULL)
+    ),
+    FOREIGN KEY (macro_id) REFERENCES macros(id) ON DELETE CASCADE,
+    FOREIGN KEY (label_id) REFERENCES ticket_labels(id) ON DELETE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES support_team(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (macro_id, position)
+);
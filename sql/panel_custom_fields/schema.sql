@@ -0,0 +1,23 @@
+DO $$
+BEGIN
+	CREATE TYPE custom_field_type AS ENUM ('text', 'number', 'select', 'date');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS panel_custom_fields
+(
+    id       SERIAL            NOT NULL,
+    panel_id int               NOT NULL,
+    name     VARCHAR(64)       NOT NULL,
+    type     custom_field_type NOT NULL,
+    options  VARCHAR(100)[]    NOT NULL DEFAULT '{}',
+    required bool              NOT NULL DEFAULT 'f',
+    position int               NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE (panel_id, name),
+    FOREIGN KEY (panel_id) REFERENCES panels (panel_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT panel_custom_fields_select_options CHECK (type != 'select' OR cardinality(options) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS panel_custom_fields_panel_id ON panel_custom_fields (panel_id);
@@ -0,0 +1,28 @@
+WITH due AS (
+    SELECT scheduled_ticket_actions.id
+    FROM scheduled_ticket_actions
+    INNER JOIN tickets
+        ON tickets.guild_id = scheduled_ticket_actions.guild_id AND tickets.id = scheduled_ticket_actions.ticket_id
+    WHERE tickets.open AND (
+        (scheduled_ticket_actions.status = 'pending' AND scheduled_ticket_actions.run_at <= NOW())
+        OR
+        (scheduled_ticket_actions.status = 'running' AND scheduled_ticket_actions.locked_until < NOW())
+    )
+    ORDER BY scheduled_ticket_actions.run_at
+    LIMIT $1
+    FOR UPDATE OF scheduled_ticket_actions SKIP LOCKED
+), claimed AS (
+    UPDATE scheduled_ticket_actions
+    SET status       = CASE WHEN scheduled_ticket_actions.attempts >= scheduled_ticket_actions.max_attempts THEN 'failed' ELSE 'running' END::scheduled_ticket_action_status,
+        attempts     = LEAST(scheduled_ticket_actions.attempts + 1, scheduled_ticket_actions.max_attempts),
+        locked_until = NOW() + $2::INTERVAL,
+        last_error   = CASE WHEN scheduled_ticket_actions.attempts >= scheduled_ticket_actions.max_attempts THEN 'lease expired' ELSE scheduled_ticket_actions.last_error END,
+        finished_at  = CASE WHEN scheduled_ticket_actions.attempts >= scheduled_ticket_actions.max_attempts THEN NOW() END
+    FROM due
+    WHERE scheduled_ticket_actions.id = due.id
+    RETURNING scheduled_ticket_actions.*
+)
+SELECT id, guild_id, ticket_id, action, payload, created_by, idempotency_key, cancel_on_activity, run_at, status, attempts, max_attempts, locked_until, last_error, created_at, finished_at
+FROM claimed
+WHERE status = 'running'
+ORDER BY run_at;
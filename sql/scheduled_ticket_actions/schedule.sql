@@ -0,0 +1,4 @@
+INSERT INTO scheduled_ticket_actions (guild_id, ticket_id, action, payload, created_by, idempotency_key, cancel_on_activity, run_at, max_attempts)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (guild_id, idempotency_key) WHERE idempotency_key IS NOT NULL DO UPDATE SET idempotency_key = EXCLUDED.idempotency_key
+RETURNING id, (xmax = 0);
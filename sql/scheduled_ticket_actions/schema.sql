@@ -0,0 +1,39 @@
+DO $$
+BEGIN
+	CREATE TYPE scheduled_ticket_action_type AS ENUM ('close', 'remind', 'unclaim', 'escalate', 'change_status', 'post_message');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+DO $$
+BEGIN
+	CREATE TYPE scheduled_ticket_action_status AS ENUM ('pending', 'running', 'completed', 'cancelled', 'failed');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS scheduled_ticket_actions (
+    id BIGSERIAL NOT NULL,
+    guild_id INT8 NOT NULL,
+    ticket_id INT4 NOT NULL,
+    action scheduled_ticket_action_type NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_by INT8 DEFAULT NULL,
+    idempotency_key VARCHAR(255) DEFAULT NULL,
+    cancel_on_activity BOOLEAN NOT NULL DEFAULT FALSE,
+    run_at TIMESTAMPTZ NOT NULL,
+    status scheduled_ticket_action_status NOT NULL DEFAULT 'pending',
+    attempts INT2 NOT NULL DEFAULT 0,
+    max_attempts INT2 NOT NULL DEFAULT 5,
+    locked_until TIMESTAMPTZ DEFAULT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ DEFAULT NULL,
+    CONSTRAINT scheduled_ticket_actions_max_attempts CHECK (max_attempts >= 1),
+    FOREIGN KEY (guild_id, ticket_id) REFERENCES tickets(guild_id, id) ON DELETE CASCADE,
+    PRIMARY KEY (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS scheduled_ticket_actions_idempotency_key ON scheduled_ticket_actions (guild_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS scheduled_ticket_actions_ticket ON scheduled_ticket_actions (guild_id, ticket_id);
+CREATE INDEX IF NOT EXISTS scheduled_ticket_actions_pending_run_at ON scheduled_ticket_actions (run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS scheduled_ticket_actions_running_locked_until ON scheduled_ticket_actions (locked_until) WHERE status = 'running';
@@ -0,0 +1,20 @@
+WITH matches AS (
+    SELECT tags.tag_id,
+           GREATEST(
+               word_similarity($2, tags.tag_id),
+               COALESCE((SELECT MAX(word_similarity($2, tag_aliases.alias))
+                         FROM tag_aliases
+                         WHERE tag_aliases.guild_id = tags.guild_id AND tag_aliases.tag_id = tags.tag_id), 0),
+               CASE
+                   WHEN to_tsvector('simple', COALESCE(tags.content, '')) @@ websearch_to_tsquery('simple', $2) THEN 0.5
+                   ELSE COALESCE(word_similarity($2, tags.content), 0) * 0.5
+               END
+           ) AS score
+    FROM tags
+    WHERE tags.guild_id = $1
+)
+SELECT LOWER(matches.tag_id)
+FROM matches
+WHERE matches.score >= 0.3
+ORDER BY matches.score DESC, matches.tag_id
+LIMIT $3;
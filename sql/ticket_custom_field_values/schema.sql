@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS ticket_custom_field_values
+(
+    guild_id     int8             NOT NULL,
+    ticket_id    int4             NOT NULL,
+    field_id     int              NOT NULL,
+    value_text   TEXT             DEFAULT NULL,
+    value_number double precision DEFAULT NULL,
+    value_date   date             DEFAULT NULL,
+    PRIMARY KEY (guild_id, ticket_id, field_id),
+    FOREIGN KEY (guild_id, ticket_id) REFERENCES tickets (guild_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (field_id) REFERENCES panel_custom_fields (id) ON DELETE CASCADE,
+    CONSTRAINT ticket_custom_field_values_single_value CHECK (num_nonnulls(value_text, value_number, value_date) = 1)
+);
+
+CREATE INDEX IF NOT EXISTS ticket_custom_field_values_field_id ON ticket_custom_field_values (field_id);
@@ -0,0 +1,11 @@
+INSERT INTO ticket_form_responses (guild_id, ticket_id, form_id, question_id, question_position, question_label, response)
+SELECT $1, $2, form_input.form_id, form_input.id, (form_input.page - 1) * 5 + form_input.position, form_input.label, $5
+FROM form_input
+INNER JOIN forms ON form_input.form_id = forms.form_id
+WHERE form_input.id = $4 AND form_input.form_id = $3 AND forms.guild_id = $1
+ON CONFLICT (guild_id, ticket_id, question_position) DO UPDATE
+    SET form_id        = EXCLUDED.form_id,
+        question_id    = EXCLUDED.question_id,
+        question_label = EXCLUDED.question_label,
+        response       = EXCLUDED.response,
+        submitted_at   = EXCLUDED.submitted_at;
@@ -0,0 +1,5 @@
+SELECT question_id, question_label
+FROM ticket_form_responses
+WHERE guild_id = $1 AND form_id = $2
+GROUP BY question_id, question_label
+ORDER BY MIN(question_position), question_id, question_label;
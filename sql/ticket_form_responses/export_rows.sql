@@ -0,0 +1,4 @@
+SELECT ticket_id, submitted_at, question_id, question_label, response
+FROM ticket_form_responses
+WHERE guild_id = $1 AND form_id = $2
+ORDER BY ticket_id, question_position;
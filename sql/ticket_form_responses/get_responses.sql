@@ -0,0 +1,4 @@
+SELECT form_id, question_id, question_label, response, submitted_at
+FROM ticket_form_responses
+WHERE guild_id = $1 AND ticket_id = $2
+ORDER BY question_position;
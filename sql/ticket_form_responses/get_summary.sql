@@ -0,0 +1,17 @@
+WITH counts AS (
+    SELECT question_id, question_label, MIN(question_position) AS position, response, COUNT(*) AS count
+    FROM ticket_form_responses
+    WHERE guild_id = $1 AND form_id = $2
+    GROUP BY question_id, question_label, response
+),
+ranked AS (
+    SELECT *,
+           ROW_NUMBER() OVER (PARTITION BY question_id, question_label ORDER BY count DESC, response) AS rank,
+           SUM(count) OVER (PARTITION BY question_id, question_label)                                AS total,
+           MIN(position) OVER (PARTITION BY question_id, question_label)                             AS question_position
+    FROM counts
+)
+SELECT question_id, question_label, total, response, count
+FROM ranked
+WHERE rank <= $3
+ORDER BY question_position, question_id, question_label, rank;
@@ -0,0 +1,3 @@
+SELECT COUNT(DISTINCT ticket_id)
+FROM ticket_form_responses
+WHERE guild_id = $1 AND form_id = $2;
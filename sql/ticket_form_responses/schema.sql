@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS ticket_form_responses
+(
+    guild_id          int8         NOT NULL,
+    ticket_id         int4         NOT NULL,
+    form_id           int4,
+    question_id       int4,
+    question_position int2         NOT NULL,
+    question_label    VARCHAR(255) NOT NULL,
+    response          TEXT         NOT NULL,
+    submitted_at      timestamptz  NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (guild_id, ticket_id) REFERENCES tickets (guild_id, id) ON DELETE CASCADE,
+    FOREIGN KEY (form_id) REFERENCES forms (form_id) ON DELETE SET NULL,
+    FOREIGN KEY (question_id) REFERENCES form_input (id) ON DELETE SET NULL,
+    PRIMARY KEY (guild_id, ticket_id, question_position)
+);
+
+CREATE INDEX IF NOT EXISTS ticket_form_responses_form_id ON ticket_form_responses (form_id);
@@ -0,0 +1,3 @@
+DELETE
+FROM ticket_limit_rules
+WHERE id = $1 AND guild_id = $2;
@@ -0,0 +1,4 @@
+SELECT id, guild_id, scope, panel_id, team_id, role_id, max_open, max_per_window, "window"
+FROM ticket_limit_rules
+WHERE guild_id = $1
+ORDER BY scope, id;
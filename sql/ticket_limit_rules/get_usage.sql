@@ -0,0 +1,36 @@
+WITH rules AS (
+    SELECT r.id, r.guild_id, r.scope, r.panel_id, r.team_id, r.role_id, r.max_open, r.max_per_window, r."window"
+    FROM ticket_limit_rules r
+    WHERE r.guild_id = $1
+      AND (
+        r.scope = 'guild'
+            OR (r.scope = 'panel' AND r.panel_id = $3)
+            OR (r.scope = 'team' AND r.team_id IN (SELECT team_id FROM panel_teams WHERE panel_id = $3))
+            OR (r.scope = 'role' AND r.role_id = ANY ($4))
+        )
+    UNION ALL
+    -- The guild wide limit from ticket_limit, which defaults to 5
+    SELECT 0, $1, 'guild', NULL, NULL, NULL, COALESCE((SELECT "limit" FROM ticket_limit WHERE guild_id = $1), 5), NULL, NULL
+),
+user_tickets AS (
+    SELECT panel_id, open, open_time
+    FROM tickets
+    WHERE guild_id = $1 AND user_id = $2
+)
+SELECT r.id,
+       r.guild_id,
+       r.scope,
+       r.panel_id,
+       r.team_id,
+       r.role_id,
+       r.max_open,
+       r.max_per_window,
+       r."window",
+       COUNT(t.*) FILTER (WHERE t.open),
+       COUNT(t.*) FILTER (WHERE r."window" IS NOT NULL AND t.open_time > NOW() - r."window")
+FROM rules r
+LEFT OUTER JOIN user_tickets t
+    ON r.scope IN ('guild', 'role')
+    OR (r.scope = 'panel' AND t.panel_id = r.panel_id)
+    OR (r.scope = 'team' AND t.panel_id IN (SELECT panel_id FROM panel_teams WHERE team_id = r.team_id))
+GROUP BY r.id, r.guild_id, r.scope, r.panel_id, r.team_id, r.role_id, r.max_open, r.max_per_window, r."window";
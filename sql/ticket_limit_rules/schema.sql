@@ -0,0 +1,33 @@
+DO $$
+BEGIN
+	CREATE TYPE ticket_limit_scope AS ENUM ('guild', 'panel', 'team', 'role');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ticket_limit_rules
+(
+    id             SERIAL             NOT NULL,
+    guild_id       int8               NOT NULL,
+    scope          ticket_limit_scope NOT NULL,
+    panel_id       int     DEFAULT NULL,
+    team_id        int     DEFAULT NULL,
+    role_id        int8    DEFAULT NULL,
+    max_open       int2    DEFAULT NULL,
+    max_per_window int2    DEFAULT NULL,
+    "window"       interval DEFAULT NULL,
+    PRIMARY KEY (id),
+    UNIQUE NULLS NOT DISTINCT (guild_id, scope, panel_id, team_id, role_id),
+    FOREIGN KEY (panel_id) REFERENCES panels (panel_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (team_id) REFERENCES support_team (id) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT ticket_limit_rules_target CHECK (
+        (scope = 'guild' AND panel_id IS NULL AND team_id IS NULL AND role_id IS NULL) OR
+        (scope = 'panel' AND panel_id IS NOT NULL AND team_id IS NULL AND role_id IS NULL) OR
+        (scope = 'team' AND panel_id IS NULL AND team_id IS NOT NULL AND role_id IS NULL) OR
+        (scope = 'role' AND panel_id IS NULL AND team_id IS NULL AND role_id IS NOT NULL)
+    ),
+    CONSTRAINT ticket_limit_rules_window CHECK ((max_per_window IS NULL) = ("window" IS NULL)),
+    CONSTRAINT ticket_limit_rules_has_limit CHECK (max_open IS NOT NULL OR max_per_window IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS ticket_limit_rules_guild_id ON ticket_limit_rules (guild_id);
@@ -0,0 +1,7 @@
+INSERT INTO ticket_limit_rules (guild_id, scope, panel_id, team_id, role_id, max_open, max_per_window, "window")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (guild_id, scope, panel_id, team_id, role_id) DO UPDATE
+    SET max_open       = EXCLUDED.max_open,
+        max_per_window = EXCLUDED.max_per_window,
+        "window"       = EXCLUDED."window"
+RETURNING id;
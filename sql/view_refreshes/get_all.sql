@@ -0,0 +1,3 @@
+SELECT view_name, last_attempt_at, last_success_at, last_duration, last_error, success_count, failure_count
+FROM view_refreshes
+ORDER BY view_name;
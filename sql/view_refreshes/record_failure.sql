@@ -0,0 +1,7 @@
+INSERT INTO view_refreshes (view_name, last_attempt_at, last_success_at, last_duration, last_error, success_count, failure_count)
+VALUES ($1, $2, NULL, $3, $4, 0, 1)
+ON CONFLICT (view_name) DO UPDATE
+SET last_attempt_at = EXCLUDED.last_attempt_at,
+    last_duration   = EXCLUDED.last_duration,
+    last_error      = EXCLUDED.last_error,
+    failure_count   = view_refreshes.failure_count + 1;
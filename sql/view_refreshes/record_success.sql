@@ -0,0 +1,8 @@
+INSERT INTO view_refreshes (view_name, last_attempt_at, last_success_at, last_duration, last_error, success_count, failure_count)
+VALUES ($1, $2, $2, $3, NULL, 1, 0)
+ON CONFLICT (view_name) DO UPDATE
+SET last_attempt_at = EXCLUDED.last_attempt_at,
+    last_success_at = EXCLUDED.last_success_at,
+    last_duration   = EXCLUDED.last_duration,
+    last_error      = NULL,
+    success_count   = view_refreshes.success_count + 1;
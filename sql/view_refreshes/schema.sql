@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS view_refreshes
+(
+    "view_name"       VARCHAR(63) NOT NULL,
+    "last_attempt_at" timestamptz NOT NULL,
+    "last_success_at" timestamptz,
+    "last_duration"   interval    NOT NULL,
+    "last_error"      TEXT,
+    "success_count"   int8        NOT NULL DEFAULT 0,
+    "failure_count"   int8        NOT NULL DEFAULT 0,
+    PRIMARY KEY ("view_name")
+);
@@ -2,16 +2,15 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 	"time"
 )
 
 type StaffOverride struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newStaffOverride(db *pgxpool.Pool) *StaffOverride {
+func newStaffOverride(db *Pool) *StaffOverride {
 	return &StaffOverride{
 		db,
 	}
@@ -36,7 +35,7 @@ WHERE "guild_id" = $1;
 	var expires time.Time
 	err := s.QueryRow(ctx, query, guildId).Scan(&expires)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil
 		} else {
 			return false, err
@@ -7,11 +7,10 @@ import (
 	"github.com/TicketsBot/common/model"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type SubscriptionSkus struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -25,7 +24,7 @@ var (
 	subscriptionSkusSearch string
 )
 
-func newSubscriptionSkusTable(db *pgxpool.Pool) *SubscriptionSkus {
+func newSubscriptionSkusTable(db *Pool) *SubscriptionSkus {
 	return &SubscriptionSkus{
 		db,
 	}
@@ -45,7 +44,7 @@ func (e *SubscriptionSkus) GetSku(ctx context.Context, tx pgx.Tx, skuId uuid.UUI
 		&sku.Priority,
 		&sku.IsGlobal,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		}
 
@@ -3,14 +3,13 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type SupportTeamMembersTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newSupportTeamMembersTable(db *pgxpool.Pool) *SupportTeamMembersTable {
+func newSupportTeamMembersTable(db *Pool) *SupportTeamMembersTable {
 	return &SupportTeamMembersTable{
 		db,
 	}
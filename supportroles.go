@@ -3,14 +3,13 @@ package database
 import (
 	"context"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type SupportTeamRolesTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newSupportTeamRolesTable(db *pgxpool.Pool) *SupportTeamRolesTable {
+func newSupportTeamRolesTable(db *Pool) *SupportTeamRolesTable {
 	return &SupportTeamRolesTable{
 		db,
 	}
@@ -4,12 +4,10 @@ import (
 	"context"
 	"errors"
 	"github.com/jackc/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type SupportTeamTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type SupportTeam struct {
@@ -28,7 +26,7 @@ func NewSupportTeam(id int, guildId uint64, name string, onCallRole *uint64) Sup
 	}
 }
 
-func newSupportTeamTable(db *pgxpool.Pool) *SupportTeamTable {
+func newSupportTeamTable(db *Pool) *SupportTeamTable {
 	return &SupportTeamTable{
 		db,
 	}
@@ -114,7 +112,7 @@ func (s *SupportTeamTable) GetById(ctx context.Context, guildId uint64, id int)
 	}
 
 	if err := s.QueryRow(ctx, query, guildId, id).Scan(&team.Name, &team.OnCallRole); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return SupportTeam{}, false, nil
 		} else {
 			return SupportTeam{}, false, err
@@ -132,7 +130,7 @@ func (s *SupportTeamTable) GetByName(ctx context.Context, guildId uint64, name s
 	}
 
 	if err := s.QueryRow(ctx, query, guildId, name).Scan(&team.Id, &team.Name, &team.OnCallRole); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return SupportTeam{}, false, nil
 		} else {
 			return SupportTeam{}, false, err
@@ -2,9 +2,8 @@ package database
 
 import (
 	"context"
+	_ "embed"
 	"errors"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type Tag struct {
@@ -13,14 +12,19 @@ type Tag struct {
 	Content              *string
 	Embed                *CustomEmbedWithFields
 	ApplicationCommandId *uint64
+	// CategoryId is not written by Set, use SetCategory instead
+	CategoryId *int
 }
 
 type TagsTable struct {
-	*pgxpool.Pool
+	*Pool
 	repository *Database
 }
 
-func newTag(db *pgxpool.Pool) *TagsTable {
+//go:embed sql/tags/search.sql
+var tagsSearch string
+
+func newTag(db *Pool) *TagsTable {
 	return &TagsTable{
 		Pool: db,
 	}
@@ -37,20 +41,32 @@ CREATE TABLE IF NOT EXISTS tags(
 	PRIMARY KEY("guild_id", "tag_id")
 );
 CREATE INDEX IF NOT EXISTS tags_guild_id_idx ON tags("guild_id");
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS "category_id" int DEFAULT NULL REFERENCES tag_categories("id") ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS tags_category_id ON tags("category_id");
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 `
 }
 
+// Exists returns true if tagId is the ID or an alias of a tag in the guild
 func (t *TagsTable) Exists(ctx context.Context, guildId uint64, tagId string) (exists bool, err error) {
-	query := `SELECT EXISTS(SELECT 1 FROM tags WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2));`
+	query := `
+SELECT EXISTS(SELECT 1 FROM tags WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2))
+	OR EXISTS(SELECT 1 FROM tag_aliases WHERE "guild_id" = $1 AND "alias" = LOWER($2));`
 	err = t.QueryRow(ctx, query, guildId, tagId).Scan(&exists)
 	return
 }
 
+// Get looks up a tag by its ID or one of its aliases. The returned tag always has the tag's own ID.
 func (t *TagsTable) Get(ctx context.Context, guildId uint64, tagId string) (Tag, bool, error) {
 	query := `
-SELECT LOWER(tag_id), "guild_id", "content", "embed", "application_command_id"
+SELECT LOWER(tag_id), "guild_id", "content", "embed", "application_command_id", "category_id"
 FROM tags
-WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2);
+WHERE "guild_id" = $1 AND (
+	LOWER("tag_id") = LOWER($2)
+	OR "tag_id" = (SELECT "tag_id" FROM tag_aliases WHERE "guild_id" = $1 AND "alias" = LOWER($2))
+)
+ORDER BY LOWER("tag_id") = LOWER($2) DESC
+LIMIT 1;
 `
 
 	var tag Tag
@@ -61,10 +77,11 @@ WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2);
 		&tag.Content,
 		&embedRaw,
 		&tag.ApplicationCommandId,
+		&tag.CategoryId,
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return Tag{}, false, nil
 		} else {
 			return Tag{}, false, err
@@ -83,12 +100,13 @@ WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2);
 func (t *TagsTable) GetTagIds(ctx context.Context, guildId uint64) (ids []string, e error) {
 	query := `SELECT LOWER("tag_id") from tags WHERE "guild_id"=$1;`
 	rows, err := t.Query(ctx, query, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var id string
 		if err := rows.Scan(&id); err != nil {
@@ -104,15 +122,15 @@ func (t *TagsTable) GetTagIds(ctx context.Context, guildId uint64) (ids []string
 
 func (t *TagsTable) GetByApplicationCommandId(ctx context.Context, guildId, applicationCommandId uint64) (Tag, bool, error) {
 	query := `
-SELECT LOWER(tags.tag_id), tags.guild_id, tags.content, tags.embed, tags.application_command_id
+SELECT LOWER(tags.tag_id), tags.guild_id, tags.content, tags.embed, tags.application_command_id, tags.category_id
 FROM tags
 WHERE "guild_id" = $1 AND "application_command_id" = $2;
 `
 
 	var tag Tag
 	var embedRaw *string
-	if err := t.QueryRow(ctx, query, guildId, applicationCommandId).Scan(&tag.Id, &tag.GuildId, &tag.Content, &embedRaw, &tag.ApplicationCommandId); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+	if err := t.QueryRow(ctx, query, guildId, applicationCommandId).Scan(&tag.Id, &tag.GuildId, &tag.Content, &embedRaw, &tag.ApplicationCommandId, &tag.CategoryId); err != nil {
+		if errors.Is(err, ErrNotFound) {
 			return Tag{}, false, nil
 		}
 
@@ -130,7 +148,7 @@ WHERE "guild_id" = $1 AND "application_command_id" = $2;
 
 func (t *TagsTable) GetByGuild(ctx context.Context, guildId uint64) (map[string]Tag, error) {
 	query := `
-SELECT LOWER(tags.tag_id), tags.guild_id, tags.content, tags.embed, tags.application_command_id
+SELECT LOWER(tags.tag_id), tags.guild_id, tags.content, tags.embed, tags.application_command_id, tags.category_id
 FROM tags
 WHERE "guild_id" = $1;`
 
@@ -145,7 +163,7 @@ WHERE "guild_id" = $1;`
 	for rows.Next() {
 		var tag Tag
 		var embedRaw *string
-		if err := rows.Scan(&tag.Id, &tag.GuildId, &tag.Content, &embedRaw, &tag.ApplicationCommandId); err != nil {
+		if err := rows.Scan(&tag.Id, &tag.GuildId, &tag.Content, &embedRaw, &tag.ApplicationCommandId, &tag.CategoryId); err != nil {
 			return nil, err
 		}
 
@@ -170,12 +188,13 @@ func (t *TagsTable) GetTagCount(ctx context.Context, guildId uint64) (count int,
 func (t *TagsTable) GetStartingWith(ctx context.Context, guildId uint64, prefix string, limit int) (tagIds []string, e error) {
 	query := `SELECT LOWER("tag_id") FROM tags WHERE "guild_id"=$1 AND "tag_id" LIKE $2 || '%' LIMIT $3;`
 	rows, err := t.Query(ctx, query, guildId, prefix, limit)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var id string
 		if err := rows.Scan(&id); err != nil {
@@ -188,6 +207,77 @@ func (t *TagsTable) GetStartingWith(ctx context.Context, guildId uint64, prefix
 	return
 }
 
+// Search returns the IDs of the tags whose ID, aliases or content best match the search text, with the best matches
+// first. IDs and aliases are matched fuzzily, so that typos and partial words still match, while content must either
+// contain the search terms or closely match them, and is weighted below IDs and aliases. Every tag in the guild is
+// scored, which is cheap as guilds have few tags, so no trigram or full text indexes are used.
+func (t *TagsTable) Search(ctx context.Context, guildId uint64, search string, limit int) ([]string, error) {
+	rows, err := t.Query(ctx, tagsSearch, guildId, search, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tagIds := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		tagIds = append(tagIds, id)
+	}
+
+	return tagIds, rows.Err()
+}
+
+// GetByCategory returns the IDs of the tags in the category, or of the uncategorised tags if categoryId is nil
+func (t *TagsTable) GetByCategory(ctx context.Context, guildId uint64, categoryId *int) ([]string, error) {
+	query := `SELECT LOWER("tag_id") FROM tags WHERE "guild_id" = $1 AND "category_id" IS NOT DISTINCT FROM $2 ORDER BY "tag_id";`
+
+	rows, err := t.Query(ctx, query, guildId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tagIds := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		tagIds = append(tagIds, id)
+	}
+
+	return tagIds, rows.Err()
+}
+
+// SetCategory moves the tag into the category, or out of its category if categoryId is nil. ErrNotFound is returned if
+// the tag does not exist, or the category belongs to another guild.
+func (t *TagsTable) SetCategory(ctx context.Context, guildId uint64, tagId string, categoryId *int) error {
+	query := `
+UPDATE tags
+SET "category_id" = $3
+WHERE "guild_id" = $1
+	AND "tag_id" = (SELECT "tag_id" FROM tags WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2) ORDER BY "tag_id" = LOWER($2) DESC LIMIT 1)
+	AND ($3::int IS NULL OR EXISTS(SELECT 1 FROM tag_categories WHERE "id" = $3 AND "guild_id" = $1));`
+
+	res, err := t.Exec(ctx, query, guildId, tagId, categoryId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
 func (t *TagsTable) Set(ctx context.Context, tag Tag) error {
 	query := `
 INSERT INTO tags("tag_id", "guild_id", "content", "embed", "application_command_id")
@@ -0,0 +1,104 @@
+package database
+
+import "context"
+
+// TagAliases stores alternative names for tags. TagsTable.Get resolves aliases to the tag they point to.
+type TagAliases struct {
+	*Pool
+}
+
+func newTagAliases(db *Pool) *TagAliases {
+	return &TagAliases{
+		db,
+	}
+}
+
+func (TagAliases) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS tag_aliases(
+	"guild_id" int8 NOT NULL,
+	"alias" varchar(16) NOT NULL,
+	"tag_id" varchar(16) NOT NULL,
+	FOREIGN KEY("guild_id", "tag_id") REFERENCES tags("guild_id", "tag_id") ON DELETE CASCADE ON UPDATE CASCADE,
+	PRIMARY KEY("guild_id", "alias")
+);
+CREATE INDEX IF NOT EXISTS tag_aliases_guild_id_tag_id ON tag_aliases("guild_id", "tag_id");
+`
+}
+
+// GetByTag returns the aliases of the tag
+func (a *TagAliases) GetByTag(ctx context.Context, guildId uint64, tagId string) ([]string, error) {
+	query := `SELECT "alias" FROM tag_aliases WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2) ORDER BY "alias";`
+
+	rows, err := a.Query(ctx, query, guildId, tagId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	aliases := make([]string, 0)
+	for rows.Next() {
+		var alias string
+		if err := rows.Scan(&alias); err != nil {
+			return nil, err
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// GetAll returns the aliases of every tag in the guild, keyed by tag ID
+func (a *TagAliases) GetAll(ctx context.Context, guildId uint64) (map[string][]string, error) {
+	query := `SELECT LOWER("tag_id"), "alias" FROM tag_aliases WHERE "guild_id" = $1 ORDER BY "alias";`
+
+	rows, err := a.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	aliases := make(map[string][]string)
+	for rows.Next() {
+		var tagId, alias string
+		if err := rows.Scan(&tagId, &alias); err != nil {
+			return nil, err
+		}
+
+		aliases[tagId] = append(aliases[tagId], alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// Create adds an alias for the tag. ErrConflict is returned if the alias is already in use, either as an alias or as
+// the ID of a tag, and ErrForeignKey is returned if the tag does not exist. The alias points to the tag's ID as stored,
+// which may not be lower case for older tags.
+func (a *TagAliases) Create(ctx context.Context, guildId uint64, alias, tagId string) error {
+	query := `
+INSERT INTO tag_aliases("guild_id", "alias", "tag_id")
+SELECT $1, LOWER($2), COALESCE(
+	(SELECT "tag_id" FROM tags WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($3) ORDER BY "tag_id" = LOWER($3) DESC LIMIT 1),
+	LOWER($3)
+)
+WHERE NOT EXISTS(SELECT 1 FROM tags WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2));`
+
+	res, err := a.Exec(ctx, query, guildId, alias, tagId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+func (a *TagAliases) Delete(ctx context.Context, guildId uint64, alias string) (err error) {
+	_, err = a.Exec(ctx, `DELETE FROM tag_aliases WHERE "guild_id" = $1 AND "alias" = LOWER($2);`, guildId, alias)
+	return
+}
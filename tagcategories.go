@@ -0,0 +1,75 @@
+package database
+
+import "context"
+
+type TagCategory struct {
+	Id      int    `json:"id"`
+	GuildId uint64 `json:"guild_id,string"`
+	Name    string `json:"name"`
+}
+
+// TagCategories groups a guild's tags. Tags are assigned to a category with TagsTable.SetCategory, and become
+// uncategorised if their category is deleted.
+type TagCategories struct {
+	*Pool
+}
+
+func newTagCategories(db *Pool) *TagCategories {
+	return &TagCategories{
+		db,
+	}
+}
+
+func (TagCategories) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS tag_categories(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"name" VARCHAR(32) NOT NULL,
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);
+`
+}
+
+func (c *TagCategories) GetAll(ctx context.Context, guildId uint64) ([]TagCategory, error) {
+	query := `SELECT "id", "guild_id", "name" FROM tag_categories WHERE "guild_id" = $1 ORDER BY "name";`
+
+	rows, err := c.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	categories := make([]TagCategory, 0)
+	for rows.Next() {
+		var category TagCategory
+		if err := rows.Scan(&category.Id, &category.GuildId, &category.Name); err != nil {
+			return nil, err
+		}
+
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// Create returns ErrConflict if the guild already has a category with the same name
+func (c *TagCategories) Create(ctx context.Context, guildId uint64, name string) (id int, err error) {
+	query := `INSERT INTO tag_categories("guild_id", "name") VALUES($1, $2) RETURNING "id";`
+	err = c.QueryRow(ctx, query, guildId, name).Scan(&id)
+	return
+}
+
+// Rename returns ErrConflict if the guild already has a category with the new name
+func (c *TagCategories) Rename(ctx context.Context, guildId uint64, categoryId int, name string) (err error) {
+	query := `UPDATE tag_categories SET "name" = $3 WHERE "id" = $1 AND "guild_id" = $2;`
+	_, err = c.Exec(ctx, query, categoryId, guildId, name)
+	return
+}
+
+func (c *TagCategories) Delete(ctx context.Context, guildId uint64, categoryId int) (err error) {
+	_, err = c.Exec(ctx, `DELETE FROM tag_categories WHERE "id" = $1 AND "guild_id" = $2;`, categoryId, guildId)
+	return
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// TagUsage records a tag being sent. TicketId is nil if the tag was used outside of a ticket, or the ticket has since
+// been deleted.
+type TagUsage struct {
+	GuildId  uint64    `json:"guild_id,string"`
+	TagId    string    `json:"tag_id"`
+	UserId   uint64    `json:"user_id,string"`
+	TicketId *int      `json:"ticket_id"`
+	UsedAt   time.Time `json:"used_at"`
+}
+
+type TagUsageCount struct {
+	TagId string `json:"tag_id"`
+	Uses  int    `json:"uses"`
+}
+
+type TagUsages struct {
+	*Pool
+}
+
+func newTagUsages(db *Pool) *TagUsages {
+	return &TagUsages{
+		db,
+	}
+}
+
+func (TagUsages) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS tag_usages(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"tag_id" varchar(16) NOT NULL,
+	"user_id" int8 NOT NULL,
+	"ticket_id" int4 DEFAULT NULL,
+	"used_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("guild_id", "tag_id") REFERENCES tags("guild_id", "tag_id") ON DELETE CASCADE ON UPDATE CASCADE,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE SET NULL ("ticket_id"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS tag_usages_guild_id_used_at ON tag_usages("guild_id", "used_at");
+CREATE INDEX IF NOT EXISTS tag_usages_guild_id_tag_id ON tag_usages("guild_id", "tag_id");
+`
+}
+
+// Record logs a use of the tag. tagId must be the tag's own ID, as returned by TagsTable.Get, rather than an alias.
+// ErrNotFound is returned if the tag does not exist.
+func (u *TagUsages) Record(ctx context.Context, guildId uint64, tagId string, userId uint64, ticketId *int) error {
+	query := `
+INSERT INTO tag_usages("guild_id", "tag_id", "user_id", "ticket_id")
+SELECT tags.guild_id, tags.tag_id, $3, $4
+FROM tags
+WHERE tags.guild_id = $1 AND LOWER(tags.tag_id) = LOWER($2)
+ORDER BY tags.tag_id = LOWER($2) DESC
+LIMIT 1;`
+
+	res, err := u.Exec(ctx, query, guildId, tagId, userId, ticketId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// GetMostUsed returns up to limit of the guild's tags that have been used within the interval, e.g. the last week,
+// with the most used first
+func (u *TagUsages) GetMostUsed(ctx context.Context, guildId uint64, interval time.Duration, limit int) ([]TagUsageCount, error) {
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT LOWER("tag_id"), COUNT(*)
+FROM tag_usages
+WHERE "guild_id" = $1 AND "used_at" > NOW() - $2::interval
+GROUP BY LOWER("tag_id")
+ORDER BY COUNT(*) DESC, LOWER("tag_id")
+LIMIT $3;`
+
+	rows, err := u.Query(ctx, query, guildId, parsedInterval, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := make([]TagUsageCount, 0)
+	for rows.Next() {
+		var count TagUsageCount
+		if err := rows.Scan(&count.TagId, &count.Uses); err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// GetRecent returns the most recent uses of the tag, newest first
+func (u *TagUsages) GetRecent(ctx context.Context, guildId uint64, tagId string, limit int) ([]TagUsage, error) {
+	query := `
+SELECT "guild_id", LOWER("tag_id"), "user_id", "ticket_id", "used_at"
+FROM tag_usages
+WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2)
+ORDER BY "used_at" DESC
+LIMIT $3;`
+
+	rows, err := u.Query(ctx, query, guildId, tagId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	usages := make([]TagUsage, 0)
+	for rows.Next() {
+		var usage TagUsage
+		if err := rows.Scan(&usage.GuildId, &usage.TagId, &usage.UserId, &usage.TicketId, &usage.UsedAt); err != nil {
+			return nil, err
+		}
+
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+// GetUseCount returns the number of times the tag has been used
+func (u *TagUsages) GetUseCount(ctx context.Context, guildId uint64, tagId string) (count int, err error) {
+	query := `SELECT COUNT(*) FROM tag_usages WHERE "guild_id" = $1 AND LOWER("tag_id") = LOWER($2);`
+	err = u.QueryRow(ctx, query, guildId, tagId).Scan(&count)
+	return
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type TicketAssigneeRole string
+
+const (
+	TicketAssigneeRolePrimary   TicketAssigneeRole = "primary"
+	TicketAssigneeRoleSecondary TicketAssigneeRole = "secondary"
+)
+
+// TicketAssignment records a period during which a staff member was assigned to a ticket. A ticket has at most one
+// active primary assignee, who is also stored in ticket_claims, and any number of secondary assignees. When the primary
+// assignee is replaced, the new assignment's PreviousAssignmentId points to the assignment it took over from.
+type TicketAssignment struct {
+	Id                   int64              `json:"id"`
+	GuildId              uint64             `json:"guild_id,string"`
+	TicketId             int                `json:"ticket_id"`
+	UserId               uint64             `json:"user_id,string"`
+	Role                 TicketAssigneeRole `json:"role"`
+	AssignedBy           *uint64            `json:"assigned_by,string"`
+	ClaimedAt            time.Time          `json:"claimed_at"`
+	ReleasedAt           *time.Time         `json:"released_at"`
+	ReleasedBy           *uint64            `json:"released_by,string"`
+	ReleaseReason        *string            `json:"release_reason"`
+	PreviousAssignmentId *int64             `json:"previous_assignment_id"`
+}
+
+type TicketAssignments struct {
+	*Pool
+}
+
+func newTicketAssignments(db *Pool) *TicketAssignments {
+	return &TicketAssignments{
+		db,
+	}
+}
+
+func (TicketAssignments) Schema() string {
+	return `
+DO $$
+BEGIN
+	CREATE TYPE ticket_assignee_role AS ENUM ('primary', 'secondary');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ticket_assignments(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"user_id" int8 NOT NULL,
+	"role" ticket_assignee_role NOT NULL,
+	"assigned_by" int8 DEFAULT NULL,
+	"claimed_at" timestamptz NOT NULL DEFAULT NOW(),
+	"released_at" timestamptz DEFAULT NULL,
+	"released_by" int8 DEFAULT NULL,
+	"release_reason" VARCHAR(255) DEFAULT NULL,
+	"previous_assignment_id" int8 DEFAULT NULL,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	FOREIGN KEY("previous_assignment_id") REFERENCES ticket_assignments("id") ON DELETE SET NULL,
+	PRIMARY KEY("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS ticket_assignments_active_primary ON ticket_assignments("guild_id", "ticket_id") WHERE "role" = 'primary' AND "released_at" IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS ticket_assignments_active_user ON ticket_assignments("guild_id", "ticket_id", "user_id") WHERE "released_at" IS NULL;
+CREATE INDEX IF NOT EXISTS ticket_assignments_guild_id_user_id ON ticket_assignments("guild_id", "user_id");
+`
+}
+
+// ticketAssignmentsClaimsBackfill makes existing claims active primary assignments, and is run once as a migration.
+// The time they were claimed was not recorded, so the ticket's open time is used instead.
+const ticketAssignmentsClaimsBackfill = `
+INSERT INTO ticket_assignments("guild_id", "ticket_id", "user_id", "role", "claimed_at")
+SELECT ticket_claims.guild_id, ticket_claims.ticket_id, ticket_claims.user_id, 'primary', tickets.open_time
+FROM ticket_claims
+INNER JOIN tickets ON tickets.guild_id = ticket_claims.guild_id AND tickets.id = ticket_claims.ticket_id
+ON CONFLICT("guild_id", "ticket_id") WHERE "role" = 'primary' AND "released_at" IS NULL DO NOTHING;
+`
+
+const ticketAssignmentColumns = `"id", "guild_id", "ticket_id", "user_id", "role", "assigned_by", "claimed_at", "released_at", "released_by", "release_reason", "previous_assignment_id"`
+
+// GetActive returns the current assignees of the ticket, with the primary assignee first
+func (a *TicketAssignments) GetActive(ctx context.Context, guildId uint64, ticketId int) ([]TicketAssignment, error) {
+	query := `
+SELECT ` + ticketAssignmentColumns + `
+FROM ticket_assignments
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "released_at" IS NULL
+ORDER BY "role", "claimed_at";`
+
+	return a.query(ctx, query, guildId, ticketId)
+}
+
+// GetHistory returns every assignment of the ticket, including released assignments, in the order they were made
+func (a *TicketAssignments) GetHistory(ctx context.Context, guildId uint64, ticketId int) ([]TicketAssignment, error) {
+	query := `
+SELECT ` + ticketAssignmentColumns + `
+FROM ticket_assignments
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "claimed_at", "id";`
+
+	return a.query(ctx, query, guildId, ticketId)
+}
+
+// Add assigns the user to the ticket. Adding a primary assignee replaces the current primary assignee, recording it as
+// a handoff. If the user is already assigned with the same role, Add does nothing.
+func (a *TicketAssignments) Add(ctx context.Context, guildId uint64, ticketId int, userId uint64, role TicketAssigneeRole, assignedBy *uint64) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := a.AddTx(ctx, tx, guildId, ticketId, userId, role, assignedBy, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Handoff replaces the primary assignee of the ticket with toUserId, recording who made the handoff and why
+func (a *TicketAssignments) Handoff(ctx context.Context, guildId uint64, ticketId int, toUserId, handedOffBy uint64, reason *string) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := a.AddTx(ctx, tx, guildId, ticketId, toUserId, TicketAssigneeRolePrimary, &handedOffBy, reason); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AddTx is the transactional form of Add. reason is recorded against any assignment that is released as a result.
+func (a *TicketAssignments) AddTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	guildId uint64,
+	ticketId int,
+	userId uint64,
+	role TicketAssigneeRole,
+	assignedBy *uint64,
+	reason *string,
+) error {
+	// Lock the ticket, so that concurrent changes to its assignees are applied in turn
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM tickets WHERE "guild_id" = $1 AND "id" = $2 FOR NO KEY UPDATE;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	var currentRole *TicketAssigneeRole
+	currentQuery := `SELECT "role" FROM ticket_assignments WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "user_id" = $3 AND "released_at" IS NULL;`
+	if err := tx.QueryRow(ctx, currentQuery, guildId, ticketId, userId).Scan(&currentRole); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	if currentRole != nil && *currentRole == role {
+		return nil
+	}
+
+	// Release the user's existing assignment if their role is changing
+	if currentRole != nil {
+		if _, err := a.releaseTx(ctx, tx, guildId, ticketId, userId, assignedBy, reason); err != nil {
+			return err
+		}
+	}
+
+	var previousId *int64
+	if role == TicketAssigneeRolePrimary {
+		releaseQuery := `
+UPDATE ticket_assignments
+SET "released_at" = NOW(), "released_by" = $3, "release_reason" = $4
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "role" = 'primary' AND "released_at" IS NULL
+RETURNING "id";`
+
+		if err := tx.QueryRow(ctx, releaseQuery, guildId, ticketId, assignedBy, reason).Scan(&previousId); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+
+	insertQuery := `
+INSERT INTO ticket_assignments("guild_id", "ticket_id", "user_id", "role", "assigned_by", "previous_assignment_id")
+VALUES($1, $2, $3, $4, $5, $6);`
+
+	if _, err := tx.Exec(ctx, insertQuery, guildId, ticketId, userId, role, assignedBy, previousId); err != nil {
+		return err
+	}
+
+	if role == TicketAssigneeRolePrimary {
+		if _, err := tx.Exec(ctx, ticketClaimsSet, guildId, ticketId, userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Release ends the user's assignment to the ticket. ErrNotFound is returned if the user is not assigned.
+func (a *TicketAssignments) Release(ctx context.Context, guildId uint64, ticketId int, userId uint64, releasedBy *uint64, reason *string) error {
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	released, err := a.releaseTx(ctx, tx, guildId, ticketId, userId, releasedBy, reason)
+	if err != nil {
+		return err
+	}
+
+	if !released {
+		return ErrNotFound
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReleasePrimaryTx ends the assignment of the ticket's primary assignee, if it has one
+func (a *TicketAssignments) ReleasePrimaryTx(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId int, releasedBy *uint64, reason *string) error {
+	query := `
+UPDATE ticket_assignments
+SET "released_at" = NOW(), "released_by" = $3, "release_reason" = $4
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "role" = 'primary' AND "released_at" IS NULL;`
+
+	if _, err := tx.Exec(ctx, query, guildId, ticketId, releasedBy, reason); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `DELETE FROM ticket_claims WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId)
+	return err
+}
+
+func (a *TicketAssignments) releaseTx(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId int, userId uint64, releasedBy *uint64, reason *string) (bool, error) {
+	query := `
+UPDATE ticket_assignments
+SET "released_at" = NOW(), "released_by" = $4, "release_reason" = $5
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "user_id" = $3 AND "released_at" IS NULL
+RETURNING "role";`
+
+	var role TicketAssigneeRole
+	if err := tx.QueryRow(ctx, query, guildId, ticketId, userId, releasedBy, reason).Scan(&role); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if role == TicketAssigneeRolePrimary {
+		if _, err := tx.Exec(ctx, `DELETE FROM ticket_claims WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (a *TicketAssignments) query(ctx context.Context, query string, args ...interface{}) ([]TicketAssignment, error) {
+	rows, err := a.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	assignments := make([]TicketAssignment, 0)
+	for rows.Next() {
+		var assignment TicketAssignment
+		if err := rows.Scan(
+			&assignment.Id,
+			&assignment.GuildId,
+			&assignment.TicketId,
+			&assignment.UserId,
+			&assignment.Role,
+			&assignment.AssignedBy,
+			&assignment.ClaimedAt,
+			&assignment.ReleasedAt,
+			&assignment.ReleasedBy,
+			&assignment.ReleaseReason,
+			&assignment.PreviousAssignmentId,
+		); err != nil {
+			return nil, err
+		}
+
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, rows.Err()
+}
@@ -2,18 +2,22 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
+// TicketClaims stores the current primary assignee of each ticket. The full assignment history, including secondary
+// assignees, is stored in TicketAssignments, which also keeps this table up to date.
 type TicketClaims struct {
-	*pgxpool.Pool
+	*Pool
+	assignments *TicketAssignments
 }
 
-func newTicketClaims(db *pgxpool.Pool) *TicketClaims {
+func newTicketClaims(db *Pool, assignments *TicketAssignments) *TicketClaims {
 	return &TicketClaims{
 		db,
+		assignments,
 	}
 }
 
@@ -31,35 +35,50 @@ CREATE TABLE IF NOT EXISTS ticket_claims(
 
 func (c *TicketClaims) Get(ctx context.Context, guildId uint64, ticketId int) (userId uint64, e error) {
 	query := `SELECT "user_id" FROM ticket_claims WHERE "guild_id" = $1 AND "ticket_id" = $2;`
-	if err := c.QueryRow(ctx, query, guildId, ticketId).Scan(&userId); err != nil && err != pgx.ErrNoRows {
+	if err := c.QueryRow(ctx, query, guildId, ticketId).Scan(&userId); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
 	return
 }
 
-func (c *TicketClaims) Set(ctx context.Context, guildId uint64, ticketId int, userId uint64) (err error) {
-	query := `INSERT INTO ticket_claims("guild_id", "ticket_id", "user_id") VALUES($1, $2, $3) ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "user_id" = $3;`
-	_, err = c.Exec(ctx, query, guildId, ticketId, userId)
-	return
+const ticketClaimsSet = `INSERT INTO ticket_claims("guild_id", "ticket_id", "user_id") VALUES($1, $2, $3) ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "user_id" = $3;`
+
+// Set makes the user the primary assignee of the ticket, recording the previous primary assignee in the history
+func (c *TicketClaims) Set(ctx context.Context, guildId uint64, ticketId int, userId uint64) error {
+	return c.assignments.Add(ctx, guildId, ticketId, userId, TicketAssigneeRolePrimary, nil)
 }
 
-func (c *TicketClaims) Delete(ctx context.Context, guildId uint64, ticketId int) (err error) {
-	query := `DELETE FROM ticket_claims WHERE "guild_id"=$1 AND "ticket_id"=$2;`
-	_, err = c.Exec(ctx, query, guildId, ticketId)
-	return
+func (c *TicketClaims) SetTx(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId int, userId uint64) error {
+	return c.assignments.AddTx(ctx, tx, guildId, ticketId, userId, TicketAssigneeRolePrimary, nil, nil)
+}
+
+// Delete unclaims the ticket. The claim is retained in the assignment history as released.
+func (c *TicketClaims) Delete(ctx context.Context, guildId uint64, ticketId int) error {
+	tx, err := c.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err := c.assignments.ReleasePrimaryTx(ctx, tx, guildId, ticketId, nil, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// stats
+// stats, counted from the assignment history so that tickets which were later handed off or unclaimed are included
 func (c *TicketClaims) GetClaimedSinceCount(ctx context.Context, guildId, userId uint64, interval time.Duration) (count int, e error) {
 	query := `
-SELECT COUNT(*)
-FROM ticket_claims
+SELECT COUNT(DISTINCT ticket_assignments.ticket_id)
+FROM ticket_assignments
 INNER JOIN tickets
-ON ticket_claims.guild_id = tickets.guild_id AND ticket_claims.ticket_id = tickets.id
-WHERE ticket_claims.guild_id = $1 AND ticket_claims.user_id = $2 AND tickets.open_time > NOW() - $3::interval;`
+ON ticket_assignments.guild_id = tickets.guild_id AND ticket_assignments.ticket_id = tickets.id
+WHERE ticket_assignments.guild_id = $1 AND ticket_assignments.user_id = $2 AND ticket_assignments.role = 'primary' AND tickets.open_time > NOW() - $3::interval;`
 
-	if err := c.QueryRow(ctx, query, guildId, userId, interval).Scan(&count); err != nil && err != pgx.ErrNoRows {
+	if err := c.QueryRow(ctx, query, guildId, userId, interval).Scan(&count); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -67,8 +86,8 @@ WHERE ticket_claims.guild_id = $1 AND ticket_claims.user_id = $2 AND tickets.ope
 }
 
 func (c *TicketClaims) GetClaimedCount(ctx context.Context, guildId, userId uint64) (count int, e error) {
-	query := `SELECT COUNT(*) FROM ticket_claims WHERE "guild_id" = $1 AND "user_id" = $2;`
-	if err := c.QueryRow(ctx, query, guildId, userId).Scan(&count); err != nil && err != pgx.ErrNoRows {
+	query := `SELECT COUNT(DISTINCT "ticket_id") FROM ticket_assignments WHERE "guild_id" = $1 AND "user_id" = $2 AND "role" = 'primary';`
+	if err := c.QueryRow(ctx, query, guildId, userId).Scan(&count); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+)
+
+type TicketCustomFieldValues struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/ticket_custom_field_values/schema.sql
+	ticketCustomFieldValuesSchema string
+)
+
+func newTicketCustomFieldValues(db *Pool) *TicketCustomFieldValues {
+	return &TicketCustomFieldValues{
+		db,
+	}
+}
+
+func (TicketCustomFieldValues) Schema() string {
+	return ticketCustomFieldValuesSchema
+}
+
+func (v *TicketCustomFieldValues) Get(ctx context.Context, guildId uint64, ticketId int) ([]CustomFieldValue, error) {
+	query := `
+SELECT "field_id", "value_text", "value_number", "value_date"
+FROM ticket_custom_field_values
+WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+
+	rows, err := v.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	values := make([]CustomFieldValue, 0)
+	for rows.Next() {
+		var value CustomFieldValue
+		if err := rows.Scan(&value.FieldId, &value.Text, &value.Number, &value.Date); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// Set stores the value of the field for the ticket. ErrNotFound is returned if the field does not belong to the panel
+// the ticket was opened from, and an error wrapping ErrInvalidCustomFieldValue if the value does not match the
+// field's type.
+func (v *TicketCustomFieldValues) Set(ctx context.Context, guildId uint64, ticketId int, value CustomFieldValue) error {
+	tx, err := v.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	field, err := getPanelCustomFieldForTicket(ctx, tx, guildId, ticketId, value.FieldId)
+	if err != nil {
+		return err
+	}
+
+	if err := field.Validate(value); err != nil {
+		return err
+	}
+
+	query := `
+INSERT INTO ticket_custom_field_values("guild_id", "ticket_id", "field_id", "value_text", "value_number", "value_date")
+VALUES($1, $2, $3, $4, $5, $6)
+ON CONFLICT("guild_id", "ticket_id", "field_id") DO UPDATE
+SET "value_text" = EXCLUDED.value_text, "value_number" = EXCLUDED.value_number, "value_date" = EXCLUDED.value_date;`
+
+	if _, err := tx.Exec(ctx, query, guildId, ticketId, value.FieldId, value.Text, value.Number, value.Date); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (v *TicketCustomFieldValues) Delete(ctx context.Context, guildId uint64, ticketId, fieldId int) (err error) {
+	query := `DELETE FROM ticket_custom_field_values WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "field_id" = $3;`
+	_, err = v.Exec(ctx, query, guildId, ticketId, fieldId)
+	return
+}
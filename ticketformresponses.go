@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// TicketFormResponse holds the answers submitted on the form shown when the ticket was opened. The question labels
+// are snapshots taken at submission time, so they are retained if the form is later edited or deleted.
+type TicketFormResponse struct {
+	GuildId     uint64             `json:"guild_id,string"`
+	TicketId    int                `json:"ticket_id"`
+	FormId      *int               `json:"form_id"`
+	SubmittedAt *time.Time         `json:"submitted_at"`
+	Responses   []QuestionResponse `json:"responses"`
+}
+
+type FormResponseSummary struct {
+	FormId      int                   `json:"form_id"`
+	TicketCount int                   `json:"ticket_count"`
+	Questions   []FormQuestionSummary `json:"questions"`
+}
+
+// FormQuestionSummary aggregates the answers to a single question. Questions that have since been deleted have a
+// nil QuestionId.
+type FormQuestionSummary struct {
+	QuestionId    *int                `json:"question_id"`
+	Question      string              `json:"question"`
+	ResponseCount int                 `json:"response_count"`
+	TopResponses  []FormResponseCount `json:"top_responses"`
+}
+
+type FormResponseCount struct {
+	Response string `json:"response"`
+	Count    int    `json:"count"`
+}
+
+type TicketFormResponses struct {
+	*Pool
+}
+
+func newTicketFormResponses(db *Pool) *TicketFormResponses {
+	return &TicketFormResponses{
+		db,
+	}
+}
+
+var (
+	//go:embed sql/ticket_form_responses/schema.sql
+	ticketFormResponsesSchema string
+
+	//go:embed sql/ticket_form_responses/add_response.sql
+	ticketFormResponsesAdd string
+
+	//go:embed sql/ticket_form_responses/get_responses.sql
+	ticketFormResponsesGet string
+
+	//go:embed sql/ticket_form_responses/get_summary.sql
+	ticketFormResponsesGetSummary string
+
+	//go:embed sql/ticket_form_responses/get_ticket_count.sql
+	ticketFormResponsesGetTicketCount string
+
+	//go:embed sql/ticket_form_responses/export_columns.sql
+	ticketFormResponsesExportColumns string
+
+	//go:embed sql/ticket_form_responses/export_rows.sql
+	ticketFormResponsesExportRows string
+)
+
+func (TicketFormResponses) Schema() string {
+	return ticketFormResponsesSchema
+}
+
+// AddResponses stores the answers submitted on the form, keyed by form input ID. Answers to inputs that do not belong
+// to the form are ignored.
+func (r *TicketFormResponses) AddResponses(ctx context.Context, guildId uint64, ticketId, formId int, responses map[int]string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	for questionId, response := range responses {
+		if _, err := tx.Exec(ctx, ticketFormResponsesAdd, guildId, ticketId, formId, questionId, response); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TicketFormResponses) GetResponses(ctx context.Context, guildId uint64, ticketId int) (TicketFormResponse, error) {
+	rows, err := r.Query(ctx, ticketFormResponsesGet, guildId, ticketId)
+	if err != nil {
+		return TicketFormResponse{}, err
+	}
+
+	defer rows.Close()
+
+	res := TicketFormResponse{
+		GuildId:   guildId,
+		TicketId:  ticketId,
+		Responses: make([]QuestionResponse, 0),
+	}
+
+	for rows.Next() {
+		var response QuestionResponse
+		var formId *int
+		var submittedAt time.Time
+		if err := rows.Scan(&formId, &response.QuestionId, &response.Question, &response.Response, &submittedAt); err != nil {
+			return TicketFormResponse{}, err
+		}
+
+		res.FormId = formId
+		res.SubmittedAt = &submittedAt
+		res.Responses = append(res.Responses, response)
+	}
+
+	return res, rows.Err()
+}
+
+// GetSummary aggregates the responses submitted on the form, returning the topResponses most common answers to each
+// question.
+func (r *TicketFormResponses) GetSummary(ctx context.Context, guildId uint64, formId, topResponses int) (FormResponseSummary, error) {
+	summary := FormResponseSummary{
+		FormId:    formId,
+		Questions: make([]FormQuestionSummary, 0),
+	}
+
+	if err := r.QueryRow(ctx, ticketFormResponsesGetTicketCount, guildId, formId).Scan(&summary.TicketCount); err != nil {
+		return FormResponseSummary{}, err
+	}
+
+	rows, err := r.Query(ctx, ticketFormResponsesGetSummary, guildId, formId, topResponses)
+	if err != nil {
+		return FormResponseSummary{}, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var question FormQuestionSummary
+		var count FormResponseCount
+		if err := rows.Scan(&question.QuestionId, &question.Question, &question.ResponseCount, &count.Response, &count.Count); err != nil {
+			return FormResponseSummary{}, err
+		}
+
+		// Rows are ordered by question, so only the previous question needs to be checked
+		if n := len(summary.Questions); n > 0 && summary.Questions[n-1].isSameQuestion(question) {
+			summary.Questions[n-1].TopResponses = append(summary.Questions[n-1].TopResponses, count)
+		} else {
+			question.TopResponses = []FormResponseCount{count}
+			summary.Questions = append(summary.Questions, question)
+		}
+	}
+
+	return summary, rows.Err()
+}
+
+// ExportCSV writes every submission of the form as CSV, with one row per ticket and one column per question
+func (r *TicketFormResponses) ExportCSV(ctx context.Context, guildId uint64, formId int, w io.Writer) error {
+	columns, err := r.getExportColumns(ctx, guildId, formId)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := []string{"ticket_id", "submitted_at"}
+	for _, column := range columns {
+		header = append(header, column.Question)
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	rows, err := r.Query(ctx, ticketFormResponsesExportRows, guildId, formId)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	var record []string
+	currentTicketId := -1
+	for rows.Next() {
+		var ticketId int
+		var submittedAt time.Time
+		var question FormQuestionSummary
+		var response string
+		if err := rows.Scan(&ticketId, &submittedAt, &question.QuestionId, &question.Question, &response); err != nil {
+			return err
+		}
+
+		if ticketId != currentTicketId {
+			if record != nil {
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+
+			currentTicketId = ticketId
+			record = make([]string, len(header))
+			record[0] = strconv.Itoa(ticketId)
+			record[1] = submittedAt.UTC().Format(time.RFC3339)
+		}
+
+		for i, column := range columns {
+			if column.isSameQuestion(question) {
+				record[i+2] = response
+				break
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if record != nil {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *TicketFormResponses) getExportColumns(ctx context.Context, guildId uint64, formId int) ([]FormQuestionSummary, error) {
+	rows, err := r.Query(ctx, ticketFormResponsesExportColumns, guildId, formId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var columns []FormQuestionSummary
+	for rows.Next() {
+		var column FormQuestionSummary
+		if err := rows.Scan(&column.QuestionId, &column.Question); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+func (s FormQuestionSummary) isSameQuestion(other FormQuestionSummary) bool {
+	if (s.QuestionId == nil) != (other.QuestionId == nil) {
+		return false
+	}
+
+	if s.QuestionId != nil && *s.QuestionId != *other.QuestionId {
+		return false
+	}
+
+	return s.Question == other.Question
+}
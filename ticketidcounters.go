@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+)
+
+// TicketIdCounters stores the most recently allocated ticket ID for each guild. Ticket IDs are allocated by
+// incrementing the counter row, which holds a row lock until the allocating transaction ends, so concurrent ticket
+// opens within a guild are serialised, rather than colliding on the tickets primary key.
+type TicketIdCounters struct {
+	*Pool
+}
+
+func newTicketIdCounters(db *Pool) *TicketIdCounters {
+	return &TicketIdCounters{
+		db,
+	}
+}
+
+func (c TicketIdCounters) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_id_counters(
+	"guild_id" int8 NOT NULL,
+	"last_id" int4 NOT NULL,
+	PRIMARY KEY("guild_id")
+);
+`
+}
+
+// ticketIdCountersBackfill creates the counters of guilds that already had tickets, and is run once as a migration.
+// Guilds without a counter are also handled by Next, so this only saves the fallback query on each guild's next ticket.
+const ticketIdCountersBackfill = `
+INSERT INTO ticket_id_counters("guild_id", "last_id")
+SELECT "guild_id", MAX("id")
+FROM tickets
+GROUP BY "guild_id"
+ON CONFLICT("guild_id") DO UPDATE SET "last_id" = GREATEST(ticket_id_counters.last_id, EXCLUDED.last_id);
+`
+
+// Next allocates the next ticket ID for the guild. The counter row remains locked until tx ends, so the ticket must
+// be inserted within the same transaction.
+func (c *TicketIdCounters) Next(ctx context.Context, tx pgx.Tx, guildId uint64) (int, error) {
+	query := `
+UPDATE ticket_id_counters
+SET "last_id" = "last_id" + 1
+WHERE "guild_id" = $1
+RETURNING "last_id";`
+
+	var id int
+	err := tx.QueryRow(ctx, query, guildId).Scan(&id)
+	if err == nil {
+		return id, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+
+	// First ticket in the guild since the counters were backfilled. If another transaction creates the row first,
+	// the insert falls through to the update.
+	query = `
+INSERT INTO ticket_id_counters("guild_id", "last_id")
+SELECT $1, COALESCE(MAX("id"), 0) + 1 FROM tickets WHERE "guild_id" = $1
+ON CONFLICT("guild_id") DO UPDATE SET "last_id" = ticket_id_counters.last_id + 1
+RETURNING "last_id";`
+
+	if err := tx.QueryRow(ctx, query, guildId).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
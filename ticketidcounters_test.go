@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"math/rand"
+	"os"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connectTestDatabase connects to the database in DATABASE_URI, skipping the test if it is not set. The tables are
+// created in a new schema, which is dropped when the test ends, so the database can be reused between runs.
+func connectTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	uri := os.Getenv("DATABASE_URI")
+	if uri == "" {
+		t.Skip("DATABASE_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	admin, err := pgxpool.Connect(ctx, uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema+";"); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE;"); err != nil {
+			t.Error(err)
+		}
+
+		admin.Close()
+	})
+
+	config, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Extensions are shared by the whole database, so public is kept on the search path
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	config.MaxConns = 20
+
+	pool, err := pgxpool.ConnectConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	db := NewDatabase(pool)
+	if err := createTestTables(ctx, db, pool); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// createTestTables reports a failure to create the schema as an error, as CreateTables panics
+func createTestTables(ctx context.Context, db *Database, pool *pgxpool.Pool) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to create tables: %v", r)
+		}
+	}()
+
+	db.CreateTables(ctx, pool)
+	return nil
+}
+
+func TestTicketIdsConcurrentCreate(t *testing.T) {
+	db := connectTestDatabase(t)
+
+	const tickets = 300
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	guildId := uint64(rand.Int63())
+
+	ids := make([]int, tickets)
+	errs := make([]error, tickets)
+
+	var wg sync.WaitGroup
+	for i := 0; i < tickets; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i], errs[i] = db.Tickets.Create(ctx, guildId, uint64(i+1), false, nil)
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("creating ticket %d: %v", i, err)
+		}
+	}
+
+	sort.Ints(ids)
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("expected ticket IDs 1 to %d without gaps or duplicates, got %d at position %d", tickets, id, i)
+		}
+	}
+}
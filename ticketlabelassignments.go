@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgtype"
+)
+
+type TicketLabelAssignments struct {
+	*Pool
+}
+
+func newTicketLabelAssignments(db *Pool) *TicketLabelAssignments {
+	return &TicketLabelAssignments{
+		db,
+	}
+}
+
+func (a TicketLabelAssignments) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_label_assignments(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"label_id" int4 NOT NULL,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	FOREIGN KEY("label_id") REFERENCES ticket_labels("id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id", "label_id")
+);
+CREATE INDEX IF NOT EXISTS ticket_label_assignments_label_id ON ticket_label_assignments("label_id");
+`
+}
+
+// Get returns the IDs of the labels assigned to the ticket
+func (a *TicketLabelAssignments) Get(ctx context.Context, guildId uint64, ticketId int) ([]int, error) {
+	query := `SELECT "label_id" FROM ticket_label_assignments WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+
+	rows, err := a.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	labelIds := make([]int, 0)
+	for rows.Next() {
+		var labelId int
+		if err := rows.Scan(&labelId); err != nil {
+			return nil, err
+		}
+
+		labelIds = append(labelIds, labelId)
+	}
+
+	return labelIds, rows.Err()
+}
+
+// Add assigns the label to the ticket, if the label belongs to the guild
+func (a *TicketLabelAssignments) Add(ctx context.Context, guildId uint64, ticketId, labelId int) (err error) {
+	query := `
+INSERT INTO ticket_label_assignments("guild_id", "ticket_id", "label_id")
+SELECT $1, $2, $3
+WHERE EXISTS(SELECT 1 FROM ticket_labels WHERE "id" = $3 AND "guild_id" = $1)
+ON CONFLICT("guild_id", "ticket_id", "label_id") DO NOTHING;`
+
+	_, err = a.Exec(ctx, query, guildId, ticketId, labelId)
+	return
+}
+
+func (a *TicketLabelAssignments) Remove(ctx context.Context, guildId uint64, ticketId, labelId int) (err error) {
+	query := `DELETE FROM ticket_label_assignments WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "label_id" = $3;`
+	_, err = a.Exec(ctx, query, guildId, ticketId, labelId)
+	return
+}
+
+// Set replaces the labels assigned to the ticket. Labels that do not belong to the guild are ignored.
+func (a *TicketLabelAssignments) Set(ctx context.Context, guildId uint64, ticketId int, labelIds []int) error {
+	array := &pgtype.Int4Array{}
+	if err := array.Set(labelIds); err != nil {
+		return err
+	}
+
+	tx, err := a.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ticket_label_assignments WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	query := `
+INSERT INTO ticket_label_assignments("guild_id", "ticket_id", "label_id")
+SELECT $1, $2, "id" FROM ticket_labels WHERE "guild_id" = $1 AND "id" = ANY($3);`
+
+	if _, err := tx.Exec(ctx, query, guildId, ticketId, array); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
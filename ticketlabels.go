@@ -0,0 +1,93 @@
+package database
+
+import "context"
+
+type TicketLabel struct {
+	Id      int     `json:"id"`
+	GuildId uint64  `json:"guild_id,string"`
+	Name    string  `json:"name"`
+	Colour  uint32  `json:"colour"`
+	Emoji   *string `json:"emoji"`
+}
+
+// TicketLabels stores the label definitions of each guild, which are assigned to tickets through
+// TicketLabelAssignments
+type TicketLabels struct {
+	*Pool
+}
+
+func newTicketLabels(db *Pool) *TicketLabels {
+	return &TicketLabels{
+		db,
+	}
+}
+
+func (l TicketLabels) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_labels(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"name" VARCHAR(32) NOT NULL,
+	"colour" int4 NOT NULL CONSTRAINT ticket_labels_colour_range CHECK (colour >= 0 AND colour <= 16777215),
+	"emoji" VARCHAR(64) DEFAULT NULL,
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS ticket_labels_guild_id ON ticket_labels("guild_id");
+`
+}
+
+func (l *TicketLabels) Get(ctx context.Context, guildId uint64, labelId int) (label TicketLabel, err error) {
+	query := `SELECT "id", "guild_id", "name", "colour", "emoji" FROM ticket_labels WHERE "id" = $1 AND "guild_id" = $2;`
+	err = l.QueryRow(ctx, query, labelId, guildId).Scan(&label.Id, &label.GuildId, &label.Name, &label.Colour, &label.Emoji)
+	return
+}
+
+func (l *TicketLabels) GetAll(ctx context.Context, guildId uint64) ([]TicketLabel, error) {
+	query := `SELECT "id", "guild_id", "name", "colour", "emoji" FROM ticket_labels WHERE "guild_id" = $1 ORDER BY "name";`
+
+	rows, err := l.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	labels := make([]TicketLabel, 0)
+	for rows.Next() {
+		var label TicketLabel
+		if err := rows.Scan(&label.Id, &label.GuildId, &label.Name, &label.Colour, &label.Emoji); err != nil {
+			return nil, err
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels, rows.Err()
+}
+
+// Create returns ErrConflict if the guild already has a label with the same name
+func (l *TicketLabels) Create(ctx context.Context, guildId uint64, name string, colour uint32, emoji *string) (id int, err error) {
+	query := `
+INSERT INTO ticket_labels("guild_id", "name", "colour", "emoji")
+VALUES($1, $2, $3, $4)
+RETURNING "id";`
+
+	err = l.QueryRow(ctx, query, guildId, name, colour, emoji).Scan(&id)
+	return
+}
+
+func (l *TicketLabels) Update(ctx context.Context, label TicketLabel) (err error) {
+	query := `
+UPDATE ticket_labels
+SET "name" = $3, "colour" = $4, "emoji" = $5
+WHERE "id" = $1 AND "guild_id" = $2;`
+
+	_, err = l.Exec(ctx, query, label.Id, label.GuildId, label.Name, label.Colour, label.Emoji)
+	return
+}
+
+func (l *TicketLabels) Delete(ctx context.Context, guildId uint64, labelId int) (err error) {
+	_, err = l.Exec(ctx, `DELETE FROM ticket_labels WHERE "id" = $1 AND "guild_id" = $2;`, labelId, guildId)
+	return
+}
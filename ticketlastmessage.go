@@ -2,13 +2,12 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 	"time"
 )
 
 type TicketLastMessageTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 type TicketLastMessage struct {
@@ -16,9 +15,13 @@ type TicketLastMessage struct {
 	LastMessageTime *time.Time `json:"last_message_time"`
 	UserId          *uint64    `json:"last_message_user_id"`
 	UserIsStaff     *bool      `json:"last_message_user_is_staff"`
+	// UnansweredSince is when the ticket started waiting for a staff reply: the time of the first customer message
+	// since staff last replied, or the ticket's open time if staff have never replied. It is nil if staff sent the
+	// last message.
+	UnansweredSince *time.Time `json:"unanswered_since"`
 }
 
-func newTicketLastMessageTable(db *pgxpool.Pool) *TicketLastMessageTable {
+func newTicketLastMessageTable(db *Pool) *TicketLastMessageTable {
 	return &TicketLastMessageTable{
 		db,
 	}
@@ -36,12 +39,21 @@ CREATE TABLE IF NOT EXISTS ticket_last_message(
 	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id"),
 	PRIMARY KEY("guild_id", "ticket_id")
 );
+ALTER TABLE ticket_last_message ADD COLUMN IF NOT EXISTS "unanswered_since" timestamptz DEFAULT NULL;
 `
 }
 
+// ticketLastMessageUnansweredSinceBackfill sets the wait start of tickets last messaged by a customer, and is run once
+// as a migration. Earlier messages were not recorded, so the last message is used, which may start the wait late.
+const ticketLastMessageUnansweredSinceBackfill = `
+UPDATE ticket_last_message
+SET "unanswered_since" = "last_message_time"
+WHERE NOT "user_is_staff" AND "unanswered_since" IS NULL;
+`
+
 func (m *TicketLastMessageTable) Get(ctx context.Context, guildId uint64, ticketId int) (lastMessage TicketLastMessage, e error) {
 	query := `
-SELECT "last_message_id", "last_message_time", "user_id", "user_is_staff"
+SELECT "last_message_id", "last_message_time", "user_id", "user_is_staff", "unanswered_since"
 FROM ticket_last_message
 WHERE "guild_id" = $1 AND "ticket_id" = $2;`
 
@@ -50,18 +62,27 @@ WHERE "guild_id" = $1 AND "ticket_id" = $2;`
 		&lastMessage.LastMessageTime,
 		&lastMessage.UserId,
 		&lastMessage.UserIsStaff,
-	); err != nil && err != pgx.ErrNoRows { // defaults to nil if no rows
+		&lastMessage.UnansweredSince,
+	); err != nil && !errors.Is(err, ErrNotFound) { // defaults to nil if no rows
 		e = err
 	}
 
 	return
 }
 
+// Set records the last message sent in the ticket. A staff message clears the time the ticket has been waiting since,
+// while a customer message starts the wait if staff had replied last, and otherwise leaves it unchanged.
 func (m *TicketLastMessageTable) Set(ctx context.Context, guildId uint64, ticketId int, messageId, userId uint64, userIsStaff bool) (err error) {
 	query := `
-INSERT INTO ticket_last_message("guild_id", "ticket_id", "last_message_id", "last_message_time", "user_id", "user_is_staff")
-VALUES($1, $2, $3, NOW(), $4, $5) ON CONFLICT("guild_id", "ticket_id")
-DO UPDATE SET "last_message_id" = $3, "last_message_time" = NOW(), "user_id" = $4, "user_is_staff" = $5;`
+INSERT INTO ticket_last_message("guild_id", "ticket_id", "last_message_id", "last_message_time", "user_id", "user_is_staff", "unanswered_since")
+VALUES($1, $2, $3, NOW(), $4, $5, CASE WHEN $5 THEN NULL ELSE (SELECT tickets.open_time FROM tickets WHERE tickets.guild_id = $1 AND tickets.id = $2) END)
+ON CONFLICT("guild_id", "ticket_id")
+DO UPDATE SET
+	"last_message_id" = $3,
+	"last_message_time" = NOW(),
+	"user_id" = $4,
+	"user_is_staff" = $5,
+	"unanswered_since" = CASE WHEN $5 THEN NULL ELSE COALESCE(ticket_last_message.unanswered_since, NOW()) END;`
 
 	_, err = m.Exec(ctx, query, guildId, ticketId, messageId, userId, userIsStaff)
 	return
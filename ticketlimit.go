@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type TicketLimit struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newTicketLimit(db *pgxpool.Pool) *TicketLimit {
+func newTicketLimit(db *Pool) *TicketLimit {
 	return &TicketLimit{
 		db,
 	}
@@ -28,7 +27,7 @@ CREATE TABLE IF NOT EXISTS ticket_limit(
 func (t *TicketLimit) Get(ctx context.Context, guildId uint64) (limit uint8, e error) {
 	query := `SELECT "limit" from ticket_limit WHERE "guild_id" = $1;`
 	if err := t.QueryRow(ctx, query, guildId).Scan(&limit); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			limit = 5
 		} else {
 			e = err
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"github.com/jackc/pgtype"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type TicketLimitScope string
+
+const (
+	TicketLimitScopeGuild TicketLimitScope = "guild"
+	TicketLimitScopePanel TicketLimitScope = "panel"
+	TicketLimitScopeTeam  TicketLimitScope = "team"
+	TicketLimitScopeRole  TicketLimitScope = "role"
+)
+
+// TicketLimitRule caps the number of tickets a user may have open at once (MaxOpen), and / or the number of tickets
+// they may open within a rolling window (MaxPerWindow per Window). Exactly one of PanelId, TeamId and RoleId is set,
+// according to Scope, unless Scope is TicketLimitScopeGuild.
+//
+// Panel rules count tickets opened from the panel, team rules count tickets opened from any panel assigned to the
+// team, and guild and role rules count every ticket in the guild.
+type TicketLimitRule struct {
+	Id           int              `json:"id"`
+	GuildId      uint64           `json:"guild_id,string"`
+	Scope        TicketLimitScope `json:"scope"`
+	PanelId      *int             `json:"panel_id"`
+	TeamId       *int             `json:"team_id"`
+	RoleId       *uint64          `json:"role_id,string"`
+	MaxOpen      *int             `json:"max_open"`
+	MaxPerWindow *int             `json:"max_per_window"`
+	Window       *time.Duration   `json:"window"`
+}
+
+type TicketLimitKind string
+
+const (
+	TicketLimitKindOpen   TicketLimitKind = "open"
+	TicketLimitKindWindow TicketLimitKind = "window"
+)
+
+// ExceededTicketLimit describes the limit that opening another ticket would exceed. The guild wide limit stored in
+// the TicketLimit table is reported as a guild scoped rule with an ID of 0.
+type ExceededTicketLimit struct {
+	Rule  TicketLimitRule `json:"rule"`
+	Kind  TicketLimitKind `json:"kind"`
+	Limit int             `json:"limit"`
+	Count int             `json:"count"`
+}
+
+type TicketLimitRules struct {
+	*Pool
+}
+
+var (
+	//go:embed sql/ticket_limit_rules/schema.sql
+	ticketLimitRulesSchema string
+
+	//go:embed sql/ticket_limit_rules/get_all.sql
+	ticketLimitRulesGetAll string
+
+	//go:embed sql/ticket_limit_rules/set.sql
+	ticketLimitRulesSet string
+
+	//go:embed sql/ticket_limit_rules/delete.sql
+	ticketLimitRulesDelete string
+
+	//go:embed sql/ticket_limit_rules/get_usage.sql
+	ticketLimitRulesGetUsage string
+)
+
+func newTicketLimitRules(db *Pool) *TicketLimitRules {
+	return &TicketLimitRules{
+		db,
+	}
+}
+
+func (TicketLimitRules) Schema() string {
+	return ticketLimitRulesSchema
+}
+
+func (r *TicketLimitRules) GetAll(ctx context.Context, guildId uint64) ([]TicketLimitRule, error) {
+	rows, err := r.Query(ctx, ticketLimitRulesGetAll, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var rules []TicketLimitRule
+	for rows.Next() {
+		var rule TicketLimitRule
+		if err := rows.Scan(rule.fieldPtrs()...); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// Set creates the rule, or replaces the limits of the existing rule with the same scope and target, returning its ID
+func (r *TicketLimitRules) Set(ctx context.Context, rule TicketLimitRule) (id int, err error) {
+	err = r.QueryRow(ctx, ticketLimitRulesSet,
+		rule.GuildId,
+		rule.Scope,
+		rule.PanelId,
+		rule.TeamId,
+		rule.RoleId,
+		rule.MaxOpen,
+		rule.MaxPerWindow,
+		rule.Window,
+	).Scan(&id)
+	return
+}
+
+func (r *TicketLimitRules) Delete(ctx context.Context, guildId uint64, ruleId int) (err error) {
+	_, err = r.Exec(ctx, ticketLimitRulesDelete, ruleId, guildId)
+	return
+}
+
+// CheckLimits returns the limit that would be exceeded if the user opened another ticket from the panel, or nil if
+// no limit would be exceeded. panelId may be nil if the ticket is not being opened from a panel. If several limits
+// would be exceeded, the most specific is returned: panel, then team, then role, then guild.
+func (r *TicketLimitRules) CheckLimits(ctx context.Context, guildId, userId uint64, panelId *int, roles []uint64) (*ExceededTicketLimit, error) {
+	return checkTicketLimits(ctx, r.Pool, guildId, userId, panelId, roles)
+}
+
+// CheckLimitsTx is equivalent to CheckLimits, but reads the rules and counts within tx
+func (r *TicketLimitRules) CheckLimitsTx(ctx context.Context, tx pgx.Tx, guildId, userId uint64, panelId *int, roles []uint64) (*ExceededTicketLimit, error) {
+	return checkTicketLimits(ctx, tx, guildId, userId, panelId, roles)
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+var ticketLimitScopePrecedence = []TicketLimitScope{
+	TicketLimitScopePanel,
+	TicketLimitScopeTeam,
+	TicketLimitScopeRole,
+	TicketLimitScopeGuild,
+}
+
+func checkTicketLimits(ctx context.Context, q querier, guildId, userId uint64, panelId *int, roles []uint64) (*ExceededTicketLimit, error) {
+	roleArray := &pgtype.Int8Array{}
+	if err := roleArray.Set(roles); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, ticketLimitRulesGetUsage, guildId, userId, panelId, roleArray)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	exceeded := make(map[TicketLimitScope]*ExceededTicketLimit)
+	for rows.Next() {
+		var rule TicketLimitRule
+		var openCount, windowCount int
+		if err := rows.Scan(append(rule.fieldPtrs(), &openCount, &windowCount)...); err != nil {
+			return nil, err
+		}
+
+		if _, ok := exceeded[rule.Scope]; ok {
+			continue
+		}
+
+		if rule.MaxOpen != nil && openCount >= *rule.MaxOpen {
+			exceeded[rule.Scope] = &ExceededTicketLimit{
+				Rule:  rule,
+				Kind:  TicketLimitKindOpen,
+				Limit: *rule.MaxOpen,
+				Count: openCount,
+			}
+		} else if rule.MaxPerWindow != nil && windowCount >= *rule.MaxPerWindow {
+			exceeded[rule.Scope] = &ExceededTicketLimit{
+				Rule:  rule,
+				Kind:  TicketLimitKindWindow,
+				Limit: *rule.MaxPerWindow,
+				Count: windowCount,
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, scope := range ticketLimitScopePrecedence {
+		if limit, ok := exceeded[scope]; ok {
+			return limit, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *TicketLimitRule) fieldPtrs() []interface{} {
+	return []interface{}{
+		&r.Id,
+		&r.GuildId,
+		&r.Scope,
+		&r.PanelId,
+		&r.TeamId,
+		&r.RoleId,
+		&r.MaxOpen,
+		&r.MaxPerWindow,
+		&r.Window,
+	}
+}
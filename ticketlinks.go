@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type TicketLinkType string
+
+const (
+	TicketLinkRelated TicketLinkType = "related"
+	// TicketLinkDuplicateOf marks the ticket as a duplicate of the linked ticket
+	TicketLinkDuplicateOf TicketLinkType = "duplicate_of"
+	// TicketLinkFollowUpOf marks the ticket as a follow-up of the linked ticket
+	TicketLinkFollowUpOf TicketLinkType = "follow_up_of"
+	// TicketLinkMergedInto is recorded by MergeTickets against the source ticket
+	TicketLinkMergedInto TicketLinkType = "merged_into"
+)
+
+// TicketLink links TicketId to LinkedTicketId. Links are directional: a ticket that is a duplicate of another has an
+// outgoing duplicate_of link, while the other ticket has an incoming one. Related links have no meaningful direction.
+type TicketLink struct {
+	GuildId        uint64         `json:"guild_id,string"`
+	TicketId       int            `json:"ticket_id"`
+	LinkedTicketId int            `json:"linked_ticket_id"`
+	Type           TicketLinkType `json:"type"`
+	CreatedBy      *uint64        `json:"created_by,string"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+var (
+	ErrMergeIntoSelf = errors.New("cannot merge a ticket into itself")
+	ErrTicketClosed  = errors.New("ticket is closed")
+)
+
+type TicketLinks struct {
+	*Pool
+	tickets       *TicketTable
+	closeMetadata *CloseMetadataTable
+}
+
+func newTicketLinks(db *Pool, tickets *TicketTable, closeMetadata *CloseMetadataTable) *TicketLinks {
+	return &TicketLinks{
+		db,
+		tickets,
+		closeMetadata,
+	}
+}
+
+func (TicketLinks) Schema() string {
+	return `
+DO $$
+BEGIN
+	CREATE TYPE ticket_link_type AS ENUM ('related', 'duplicate_of', 'follow_up_of', 'merged_into');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ticket_links(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"linked_ticket_id" int4 NOT NULL,
+	"type" ticket_link_type NOT NULL,
+	"created_by" int8 DEFAULT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	CONSTRAINT ticket_links_not_self CHECK ("ticket_id" != "linked_ticket_id"),
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	FOREIGN KEY("guild_id", "linked_ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id", "linked_ticket_id")
+);
+CREATE INDEX IF NOT EXISTS ticket_links_guild_id_linked_ticket_id ON ticket_links("guild_id", "linked_ticket_id");
+`
+}
+
+const ticketLinkColumns = `"guild_id", "ticket_id", "linked_ticket_id", "type", "created_by", "created_at"`
+
+// GetOutgoing returns the links from the ticket to other tickets
+func (l *TicketLinks) GetOutgoing(ctx context.Context, guildId uint64, ticketId int) ([]TicketLink, error) {
+	query := `SELECT ` + ticketLinkColumns + ` FROM ticket_links WHERE "guild_id" = $1 AND "ticket_id" = $2 ORDER BY "created_at";`
+	return l.query(ctx, query, guildId, ticketId)
+}
+
+// GetIncoming returns the links from other tickets to the ticket, e.g. the tickets that were merged into it
+func (l *TicketLinks) GetIncoming(ctx context.Context, guildId uint64, ticketId int) ([]TicketLink, error) {
+	query := `SELECT ` + ticketLinkColumns + ` FROM ticket_links WHERE "guild_id" = $1 AND "linked_ticket_id" = $2 ORDER BY "created_at";`
+	return l.query(ctx, query, guildId, ticketId)
+}
+
+// GetAll returns the links in both directions, as they are stored
+func (l *TicketLinks) GetAll(ctx context.Context, guildId uint64, ticketId int) ([]TicketLink, error) {
+	query := `
+SELECT ` + ticketLinkColumns + `
+FROM ticket_links
+WHERE "guild_id" = $1 AND ("ticket_id" = $2 OR "linked_ticket_id" = $2)
+ORDER BY "created_at";`
+
+	return l.query(ctx, query, guildId, ticketId)
+}
+
+// Create links the two tickets, replacing the type of any existing link from TicketId to LinkedTicketId. A related link
+// is not created if the tickets are already linked in the opposite direction.
+func (l *TicketLinks) Create(ctx context.Context, link TicketLink) (err error) {
+	query := `
+INSERT INTO ticket_links("guild_id", "ticket_id", "linked_ticket_id", "type", "created_by")
+SELECT $1, $2, $3, $4::ticket_link_type, $5
+WHERE $4::ticket_link_type != 'related' OR NOT EXISTS(
+	SELECT 1 FROM ticket_links WHERE "guild_id" = $1 AND "ticket_id" = $3 AND "linked_ticket_id" = $2
+)
+ON CONFLICT("guild_id", "ticket_id", "linked_ticket_id") DO UPDATE SET "type" = EXCLUDED."type", "created_by" = EXCLUDED."created_by", "created_at" = NOW();`
+
+	_, err = l.Exec(ctx, query, link.GuildId, link.TicketId, link.LinkedTicketId, link.Type, link.CreatedBy)
+	return
+}
+
+// Delete removes the link between the two tickets, in either direction
+func (l *TicketLinks) Delete(ctx context.Context, guildId uint64, ticketId, linkedTicketId int) (err error) {
+	query := `
+DELETE FROM ticket_links
+WHERE "guild_id" = $1 AND (
+	("ticket_id" = $2 AND "linked_ticket_id" = $3) OR ("ticket_id" = $3 AND "linked_ticket_id" = $2)
+);`
+
+	_, err = l.Exec(ctx, query, guildId, ticketId, linkedTicketId)
+	return
+}
+
+// MergeTickets merges the source ticket into the target ticket. The source ticket's participants, members, notes and
+// form responses are moved to the target, with the source's form responses placed after any the target already has.
+// The source ticket is then closed with a "Merged into #N" close reason, and a merged_into link is recorded from the
+// source to the target. mergedBy is recorded as the user who closed the source ticket, and is nil for automated merges.
+//
+// ErrNotFound is returned if either ticket does not exist, ErrMergeIntoSelf if they are the same ticket, and an error
+// wrapping ErrTicketClosed if either ticket is already closed.
+func (l *TicketLinks) MergeTickets(ctx context.Context, guildId uint64, sourceId, targetId int, mergedBy *uint64) error {
+	if sourceId == targetId {
+		return ErrMergeIntoSelf
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := l.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	// Lock both tickets in a consistent order, so that concurrent merges in opposite directions cannot deadlock
+	lockQuery := `SELECT "id", "open" FROM tickets WHERE "guild_id" = $1 AND "id" IN ($2, $3) ORDER BY "id" FOR NO KEY UPDATE;`
+	rows, err := tx.Query(ctx, lockQuery, guildId, sourceId, targetId)
+	if err != nil {
+		return err
+	}
+
+	open := make(map[int]bool, 2)
+	for rows.Next() {
+		var id int
+		var isOpen bool
+		if err := rows.Scan(&id, &isOpen); err != nil {
+			rows.Close()
+			return err
+		}
+
+		open[id] = isOpen
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(open) != 2 {
+		return ErrNotFound
+	}
+
+	for _, id := range []int{sourceId, targetId} {
+		if !open[id] {
+			return fmt.Errorf("%w: #%d", ErrTicketClosed, id)
+		}
+	}
+
+	participantsQuery := `
+INSERT INTO participant("guild_id", "ticket_id", "user_id")
+SELECT "guild_id", $3, "user_id" FROM participant WHERE "guild_id" = $1 AND "ticket_id" = $2
+ON CONFLICT DO NOTHING;`
+
+	if _, err := tx.Exec(ctx, participantsQuery, guildId, sourceId, targetId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM participant WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, sourceId); err != nil {
+		return err
+	}
+
+	membersQuery := `
+INSERT INTO ticket_members("guild_id", "ticket_id", "user_id")
+SELECT "guild_id", $3, "user_id" FROM ticket_members WHERE "guild_id" = $1 AND "ticket_id" = $2
+ON CONFLICT DO NOTHING;`
+
+	if _, err := tx.Exec(ctx, membersQuery, guildId, sourceId, targetId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ticket_members WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, sourceId); err != nil {
+		return err
+	}
+
+	notesQuery := `UPDATE ticket_notes SET "ticket_id" = $3 WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+	if _, err := tx.Exec(ctx, notesQuery, guildId, sourceId, targetId); err != nil {
+		return err
+	}
+
+	formResponsesQuery := `
+UPDATE ticket_form_responses
+SET "ticket_id" = $3, "question_position" = "question_position" + (
+	SELECT COALESCE(MAX("question_position") + 1, 0) FROM ticket_form_responses WHERE "guild_id" = $1 AND "ticket_id" = $3
+)
+WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+
+	if _, err := tx.Exec(ctx, formResponsesQuery, guildId, sourceId, targetId); err != nil {
+		return err
+	}
+
+	if err := l.tickets.CloseTx(ctx, tx, sourceId, guildId); err != nil {
+		return err
+	}
+
+	reason := fmt.Sprintf("Merged into #%d", targetId)
+	if err := l.closeMetadata.SetTx(ctx, tx, guildId, sourceId, CloseMetadata{Reason: &reason, ClosedBy: mergedBy}); err != nil {
+		return err
+	}
+
+	linkQuery := `
+INSERT INTO ticket_links("guild_id", "ticket_id", "linked_ticket_id", "type", "created_by")
+VALUES($1, $2, $3, 'merged_into', $4)
+ON CONFLICT("guild_id", "ticket_id", "linked_ticket_id") DO UPDATE SET "type" = 'merged_into', "created_by" = $4, "created_at" = NOW();`
+
+	if _, err := tx.Exec(ctx, linkQuery, guildId, sourceId, targetId, mergedBy); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (l *TicketLinks) query(ctx context.Context, query string, args ...interface{}) ([]TicketLink, error) {
+	rows, err := l.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	links := make([]TicketLink, 0)
+	for rows.Next() {
+		var link TicketLink
+		if err := rows.Scan(&link.GuildId, &link.TicketId, &link.LinkedTicketId, &link.Type, &link.CreatedBy, &link.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
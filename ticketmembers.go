@@ -2,15 +2,13 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type TicketMembers struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newTicketMembers(db *pgxpool.Pool) *TicketMembers {
+func newTicketMembers(db *Pool) *TicketMembers {
 	return &TicketMembers{
 		db,
 	}
@@ -33,12 +31,13 @@ CREATE INDEX IF NOT EXISTS ticket_members_guild_ticket ON ticket_members("guild_
 func (m *TicketMembers) Get(ctx context.Context, guildId uint64, ticketId int) (members []uint64, e error) {
 	query := `SELECT "user_id" FROM ticket_members WHERE "guild_id" = $1 AND "ticket_id" = $2;`
 	rows, err := m.Query(ctx, query, guildId, ticketId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var userId uint64
 		if err := rows.Scan(&userId); err != nil {
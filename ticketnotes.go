@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"github.com/jackc/pgtype"
+	"time"
+)
+
+// TicketNote is an internal comment left by staff on a ticket, which is not visible to the ticket's opener. Mentions
+// holds the IDs of the users mentioned in Body.
+type TicketNote struct {
+	Id        int64      `json:"id"`
+	GuildId   uint64     `json:"guild_id,string"`
+	TicketId  int        `json:"ticket_id"`
+	AuthorId  uint64     `json:"author_id,string"`
+	Body      string     `json:"body"`
+	Mentions  []uint64   `json:"mentions"`
+	Pinned    bool       `json:"pinned"`
+	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+}
+
+// TicketNoteRevision is a previous version of a note, recorded when the note is edited. EditedBy is the user who
+// replaced this version, at EditedAt.
+type TicketNoteRevision struct {
+	NoteId   int64     `json:"note_id"`
+	Body     string    `json:"body"`
+	Mentions []uint64  `json:"mentions"`
+	EditedBy uint64    `json:"edited_by,string"`
+	EditedAt time.Time `json:"edited_at"`
+}
+
+type TicketNotes struct {
+	*Pool
+}
+
+func newTicketNotes(db *Pool) *TicketNotes {
+	return &TicketNotes{
+		db,
+	}
+}
+
+func (TicketNotes) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_notes(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"author_id" int8 NOT NULL,
+	"body" TEXT NOT NULL CONSTRAINT ticket_notes_body_length CHECK (length("body") <= 4000),
+	"mentions" int8[] NOT NULL DEFAULT '{}',
+	"pinned" bool NOT NULL DEFAULT 'f',
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	"edited_at" timestamptz DEFAULT NULL,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS ticket_notes_guild_id_ticket_id ON ticket_notes("guild_id", "ticket_id");
+CREATE INDEX IF NOT EXISTS ticket_notes_body_fts ON ticket_notes USING GIN(to_tsvector('simple', "body"));
+
+CREATE TABLE IF NOT EXISTS ticket_note_revisions(
+	"id" BIGSERIAL NOT NULL,
+	"note_id" int8 NOT NULL,
+	"body" TEXT NOT NULL,
+	"mentions" int8[] NOT NULL DEFAULT '{}',
+	"edited_by" int8 NOT NULL,
+	"edited_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("note_id") REFERENCES ticket_notes("id") ON DELETE CASCADE,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS ticket_note_revisions_note_id ON ticket_note_revisions("note_id");
+`
+}
+
+const ticketNoteColumns = `"id", "guild_id", "ticket_id", "author_id", "body", "mentions", "pinned", "created_at", "edited_at"`
+
+func (n *TicketNotes) Get(ctx context.Context, guildId uint64, noteId int64) (note TicketNote, err error) {
+	query := `SELECT ` + ticketNoteColumns + ` FROM ticket_notes WHERE "id" = $1 AND "guild_id" = $2;`
+	err = n.QueryRow(ctx, query, noteId, guildId).Scan(note.fieldPtrs()...)
+	return
+}
+
+// GetByTicket returns the notes on the ticket, with pinned notes first and then in the order they were written
+func (n *TicketNotes) GetByTicket(ctx context.Context, guildId uint64, ticketId int) ([]TicketNote, error) {
+	query := `
+SELECT ` + ticketNoteColumns + `
+FROM ticket_notes
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "pinned" DESC, "created_at", "id";`
+
+	return n.query(ctx, query, guildId, ticketId)
+}
+
+// Search returns up to limit of the guild's notes that match the search text, using web search syntax (e.g. quoted
+// phrases and -excluded words), with the best matches first. If ticketId is not nil, only notes on that ticket are
+// searched.
+func (n *TicketNotes) Search(ctx context.Context, guildId uint64, ticketId *int, search string, limit int) ([]TicketNote, error) {
+	query := `
+SELECT ` + ticketNoteColumns + `
+FROM ticket_notes
+WHERE "guild_id" = $1
+	AND ($2::int4 IS NULL OR "ticket_id" = $2)
+	AND to_tsvector('simple', "body") @@ websearch_to_tsquery('simple', $3)
+ORDER BY ts_rank(to_tsvector('simple', "body"), websearch_to_tsquery('simple', $3)) DESC, "created_at" DESC
+LIMIT $4;`
+
+	return n.query(ctx, query, guildId, ticketId, search, limit)
+}
+
+// GetByAuthor returns the most recent notes written by the user, newest first
+func (n *TicketNotes) GetByAuthor(ctx context.Context, guildId, authorId uint64, limit int) ([]TicketNote, error) {
+	query := `
+SELECT ` + ticketNoteColumns + `
+FROM ticket_notes
+WHERE "guild_id" = $1 AND "author_id" = $2
+ORDER BY "created_at" DESC
+LIMIT $3;`
+
+	return n.query(ctx, query, guildId, authorId, limit)
+}
+
+func (n *TicketNotes) Create(ctx context.Context, note TicketNote) (id int64, err error) {
+	mentions := &pgtype.Int8Array{}
+	if err := mentions.Set(nonNilUint64s(note.Mentions)); err != nil {
+		return 0, err
+	}
+
+	query := `
+INSERT INTO ticket_notes("guild_id", "ticket_id", "author_id", "body", "mentions", "pinned")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING "id";`
+
+	err = n.QueryRow(ctx, query, note.GuildId, note.TicketId, note.AuthorId, note.Body, mentions, note.Pinned).Scan(&id)
+	return
+}
+
+// Edit replaces the body and mentions of the note, recording the previous version as a revision. ErrNotFound is
+// returned if the note does not exist.
+func (n *TicketNotes) Edit(ctx context.Context, guildId uint64, noteId int64, editorId uint64, body string, mentions []uint64) error {
+	mentionsArray := &pgtype.Int8Array{}
+	if err := mentionsArray.Set(nonNilUint64s(mentions)); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := n.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	revisionQuery := `
+INSERT INTO ticket_note_revisions("note_id", "body", "mentions", "edited_by")
+SELECT "id", "body", "mentions", $3
+FROM ticket_notes
+WHERE "id" = $1 AND "guild_id" = $2
+FOR UPDATE;`
+
+	res, err := tx.Exec(ctx, revisionQuery, noteId, guildId, editorId)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	query := `UPDATE ticket_notes SET "body" = $3, "mentions" = $4, "edited_at" = NOW() WHERE "id" = $1 AND "guild_id" = $2;`
+	if _, err := tx.Exec(ctx, query, noteId, guildId, body, mentionsArray); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetRevisions returns the previous versions of the note, oldest first
+func (n *TicketNotes) GetRevisions(ctx context.Context, guildId uint64, noteId int64) ([]TicketNoteRevision, error) {
+	query := `
+SELECT ticket_note_revisions.note_id, ticket_note_revisions.body, ticket_note_revisions.mentions, ticket_note_revisions.edited_by, ticket_note_revisions.edited_at
+FROM ticket_note_revisions
+INNER JOIN ticket_notes ON ticket_notes.id = ticket_note_revisions.note_id
+WHERE ticket_note_revisions.note_id = $1 AND ticket_notes.guild_id = $2
+ORDER BY ticket_note_revisions.edited_at, ticket_note_revisions.id;`
+
+	rows, err := n.Query(ctx, query, noteId, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revisions := make([]TicketNoteRevision, 0)
+	for rows.Next() {
+		var revision TicketNoteRevision
+		if err := rows.Scan(&revision.NoteId, &revision.Body, &revision.Mentions, &revision.EditedBy, &revision.EditedAt); err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (n *TicketNotes) SetPinned(ctx context.Context, guildId uint64, noteId int64, pinned bool) (err error) {
+	_, err = n.Exec(ctx, `UPDATE ticket_notes SET "pinned" = $3 WHERE "id" = $1 AND "guild_id" = $2;`, noteId, guildId, pinned)
+	return
+}
+
+func (n *TicketNotes) Delete(ctx context.Context, guildId uint64, noteId int64) (err error) {
+	_, err = n.Exec(ctx, `DELETE FROM ticket_notes WHERE "id" = $1 AND "guild_id" = $2;`, noteId, guildId)
+	return
+}
+
+func (n *TicketNotes) query(ctx context.Context, query string, args ...interface{}) ([]TicketNote, error) {
+	rows, err := n.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	notes := make([]TicketNote, 0)
+	for rows.Next() {
+		var note TicketNote
+		if err := rows.Scan(note.fieldPtrs()...); err != nil {
+			return nil, err
+		}
+
+		notes = append(notes, note)
+	}
+
+	return notes, rows.Err()
+}
+
+func (n *TicketNote) fieldPtrs() []interface{} {
+	return []interface{}{
+		&n.Id,
+		&n.GuildId,
+		&n.TicketId,
+		&n.AuthorId,
+		&n.Body,
+		&n.Mentions,
+		&n.Pinned,
+		&n.CreatedAt,
+		&n.EditedAt,
+	}
+}
@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type TicketPermissions struct {
@@ -13,10 +12,10 @@ type TicketPermissions struct {
 }
 
 type TicketPermissionsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newTicketPermissionsTable(db *pgxpool.Pool) *TicketPermissionsTable {
+func newTicketPermissionsTable(db *Pool) *TicketPermissionsTable {
 	return &TicketPermissionsTable{
 		db,
 	}
@@ -48,7 +47,7 @@ WHERE "guild_id" = $1;`
 	)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return TicketPermissions{
 				AttachFiles:  true,
 				EmbedLinks:   true,
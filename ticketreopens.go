@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/TicketsBot/common/model"
+	"time"
+)
+
+// TicketReopen records a ticket being reopened, along with the open/close cycle that it ended. OpenedAt is the start
+// of that cycle: the ticket's open time for the first cycle, or the time of the previous reopen otherwise. The close
+// reason, closer and service rating of the cycle are archived here, as they are cleared when the ticket is reopened.
+type TicketReopen struct {
+	Id          int64     `json:"id"`
+	GuildId     uint64    `json:"guild_id,string"`
+	TicketId    int       `json:"ticket_id"`
+	OpenedAt    time.Time `json:"opened_at"`
+	ClosedAt    time.Time `json:"closed_at"`
+	CloseReason *string   `json:"close_reason"`
+	ClosedBy    *uint64   `json:"closed_by,string"`
+	Rating      *uint8    `json:"rating"`
+	ReopenedBy  *uint64   `json:"reopened_by,string"`
+	Reason      *string   `json:"reason"`
+	ReopenedAt  time.Time `json:"reopened_at"`
+}
+
+var ErrTicketNotClosed = errors.New("ticket is not closed")
+
+type TicketReopens struct {
+	*Pool
+	tickets *TicketTable
+}
+
+func newTicketReopens(db *Pool, tickets *TicketTable) *TicketReopens {
+	return &TicketReopens{
+		db,
+		tickets,
+	}
+}
+
+func (TicketReopens) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_reopens(
+	"id" BIGSERIAL NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"opened_at" timestamptz NOT NULL,
+	"closed_at" timestamptz NOT NULL,
+	"close_reason" TEXT DEFAULT NULL,
+	"closed_by" int8 DEFAULT NULL,
+	"rating" int2 DEFAULT NULL,
+	"reopened_by" int8 DEFAULT NULL,
+	"reason" VARCHAR(255) DEFAULT NULL,
+	"reopened_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS ticket_reopens_guild_id_ticket_id ON ticket_reopens("guild_id", "ticket_id");
+`
+}
+
+// ticketCycleStart is the start of the ticket's current open/close cycle
+const ticketCycleStart = `COALESCE((SELECT MAX(ticket_reopens.reopened_at) FROM ticket_reopens WHERE ticket_reopens.guild_id = tickets.guild_id AND ticket_reopens.ticket_id = tickets.id), tickets.open_time)`
+
+// GetHistory returns the times the ticket has been reopened, oldest first
+func (r *TicketReopens) GetHistory(ctx context.Context, guildId uint64, ticketId int) ([]TicketReopen, error) {
+	query := `
+SELECT "id", "guild_id", "ticket_id", "opened_at", "closed_at", "close_reason", "closed_by", "rating", "reopened_by", "reason", "reopened_at"
+FROM ticket_reopens
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "reopened_at", "id";`
+
+	rows, err := r.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	reopens := make([]TicketReopen, 0)
+	for rows.Next() {
+		var reopen TicketReopen
+		if err := rows.Scan(
+			&reopen.Id,
+			&reopen.GuildId,
+			&reopen.TicketId,
+			&reopen.OpenedAt,
+			&reopen.ClosedAt,
+			&reopen.CloseReason,
+			&reopen.ClosedBy,
+			&reopen.Rating,
+			&reopen.ReopenedBy,
+			&reopen.Reason,
+			&reopen.ReopenedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		reopens = append(reopens, reopen)
+	}
+
+	return reopens, rows.Err()
+}
+
+// GetReopenCount returns the number of times tickets in the guild have been reopened within the interval
+func (r *TicketReopens) GetReopenCount(ctx context.Context, guildId uint64, interval time.Duration) (count int, err error) {
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT COUNT(*) FROM ticket_reopens WHERE "guild_id" = $1 AND "reopened_at" > NOW() - $2::interval;`
+	err = r.QueryRow(ctx, query, guildId, parsedInterval).Scan(&count)
+	return
+}
+
+// Reopen opens a closed ticket again. The close reason, closer and service rating of the ticket are moved into the
+// reopen history, so that the next close and rating apply to the new cycle, and the ticket's status is reset to open.
+// ErrNotFound is returned if the ticket does not exist, and ErrTicketNotClosed if it is still open.
+func (r *TicketReopens) Reopen(ctx context.Context, guildId uint64, ticketId int, reopenedBy *uint64, reason *string) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTransactionTimeout)
+	defer cancel()
+
+	tx, err := r.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var open bool
+	lockQuery := `SELECT "open" FROM tickets WHERE "guild_id" = $1 AND "id" = $2 FOR NO KEY UPDATE;`
+	if err := tx.QueryRow(ctx, lockQuery, guildId, ticketId).Scan(&open); err != nil {
+		return err
+	}
+
+	if open {
+		return ErrTicketNotClosed
+	}
+
+	historyQuery := `
+INSERT INTO ticket_reopens("guild_id", "ticket_id", "opened_at", "closed_at", "close_reason", "closed_by", "rating", "reopened_by", "reason")
+SELECT tickets.guild_id, tickets.id, ` + ticketCycleStart + `, COALESCE(tickets.close_time, NOW()), close_reason.close_reason, close_reason.closed_by, service_ratings.rating, $3, $4
+FROM tickets
+LEFT JOIN close_reason ON close_reason.guild_id = tickets.guild_id AND close_reason.ticket_id = tickets.id
+LEFT JOIN service_ratings ON service_ratings.guild_id = tickets.guild_id AND service_ratings.ticket_id = tickets.id
+WHERE tickets.guild_id = $1 AND tickets.id = $2;`
+
+	if _, err := tx.Exec(ctx, historyQuery, guildId, ticketId, reopenedBy, reason); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM close_reason WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM service_ratings WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE tickets SET "open" = TRUE, "close_time" = NULL WHERE "guild_id" = $1 AND "id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	if err := r.tickets.SetStatusTx(ctx, tx, guildId, ticketId, model.TicketStatusOpen); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -2,13 +2,12 @@ package database
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/TicketsBot/common/model"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"math"
+	"strings"
 	"time"
 )
 
@@ -29,18 +28,32 @@ type Ticket struct {
 	Status           model.TicketStatus `json:"status"`
 }
 
+// TicketQueryOptions filters tickets by each non-zero field. Tickets match if they have any of Priorities, all of
+// LabelIds and all of CustomFields.
 type TicketQueryOptions struct {
-	Id      int       `json:"id"`
-	GuildId uint64    `json:"guild_id"`
-	UserIds []uint64  `json:"user_ids"`
-	Open    *bool     `json:"open"`
-	PanelId int       `json:"panel_id"`
-	Rating  int       `json:"rating"`
-	Order   OrderType `json:"order_type"`
-	Limit   int       `json:"limit"`
-	Offset  int       `json:"offset"`
+	Id           int                `json:"id"`
+	GuildId      uint64             `json:"guild_id"`
+	UserIds      []uint64           `json:"user_ids"`
+	Open         *bool              `json:"open"`
+	PanelId      int                `json:"panel_id"`
+	Rating       int                `json:"rating"`
+	Priorities   []TicketPriority   `json:"priorities"`
+	LabelIds     []int              `json:"label_ids"`
+	CustomFields []CustomFieldValue `json:"custom_fields"`
+	Order        OrderType          `json:"order_type"`
+	Limit        int                `json:"limit"`
+	Offset       int                `json:"offset"`
 }
 
+type TicketPriority string
+
+const (
+	TicketPriorityLow    TicketPriority = "low"
+	TicketPriorityNormal TicketPriority = "normal"
+	TicketPriorityHigh   TicketPriority = "high"
+	TicketPriorityUrgent TicketPriority = "urgent"
+)
+
 type OrderType string
 
 const (
@@ -50,25 +63,44 @@ const (
 )
 
 func (o TicketQueryOptions) HasWhereClause() bool {
-	return o.Id == 0 &&
-		o.GuildId == 0 &&
-		len(o.UserIds) == 0 &&
-		o.Open == nil &&
-		o.Rating != 0
+	return o.Id != 0 ||
+		o.GuildId != 0 ||
+		len(o.UserIds) > 0 ||
+		o.Open != nil ||
+		o.PanelId > 0 ||
+		o.Rating > 0 ||
+		len(o.Priorities) > 0 ||
+		len(o.LabelIds) > 0 ||
+		len(o.CustomFields) > 0
 }
 
 type TicketTable struct {
-	*pgxpool.Pool
+	*Pool
+	idCounters *TicketIdCounters
 }
 
-func newTicketTable(db *pgxpool.Pool) *TicketTable {
+func newTicketTable(db *Pool, idCounters *TicketIdCounters) *TicketTable {
 	return &TicketTable{
 		db,
+		idCounters,
 	}
 }
 
 func (t TicketTable) Schema() string {
 	return `
+-- ticket_status is also used by category_update_queue, but must exist before the tickets table is created
+DO $$
+BEGIN
+	CREATE TYPE ticket_status AS ENUM ('OPEN', 'PENDING', 'CLOSED');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
+DO $$
+BEGIN
+	CREATE TYPE ticket_priority AS ENUM ('low', 'normal', 'high', 'urgent');
+EXCEPTION
+	WHEN duplicate_object THEN NULL;
+END $$;
 CREATE TABLE IF NOT EXISTS tickets(
 	"id" int4 NOT NULL,
 	"guild_id" int8 NOT NULL,
@@ -89,20 +121,49 @@ CREATE TABLE IF NOT EXISTS tickets(
 );
 CREATE INDEX IF NOT EXISTS tickets_channel_id ON tickets("channel_id");
 CREATE INDEX IF NOT EXISTS tickets_panel_id ON tickets("panel_id");
+CREATE INDEX IF NOT EXISTS tickets_open_guild_id_id ON tickets("guild_id", "id") WHERE "open";
+CREATE INDEX IF NOT EXISTS tickets_guild_user_open_time ON tickets("guild_id", "user_id", "open_time");
+ALTER TABLE tickets ADD COLUMN IF NOT EXISTS "priority" ticket_priority DEFAULT NULL;
 `
 }
 
-func (t *TicketTable) Create(ctx context.Context, guildId, userId uint64, isThread bool, panelId *int) (id int, err error) {
+func (t *TicketTable) Create(ctx context.Context, guildId, userId uint64, isThread bool, panelId *int) (int, error) {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	id, err := t.CreateTx(ctx, tx, guildId, userId, isThread, panelId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateTx allocates the ticket ID from TicketIdCounters within tx, and so blocks other tickets being opened in the
+// guild until tx ends.
+func (t *TicketTable) CreateTx(ctx context.Context, tx pgx.Tx, guildId, userId uint64, isThread bool, panelId *int) (int, error) {
+	id, err := t.idCounters.Next(ctx, tx, guildId)
+	if err != nil {
+		return 0, err
+	}
+
 	query := `
 INSERT INTO tickets("id", "guild_id", "user_id", "open", "open_time", "is_thread", "panel_id", "status")
-VALUES(
-       (SELECT COALESCE(MAX("id"), 0) + 1 FROM tickets WHERE "guild_id" = $1), 
-       $1, $2, true, NOW(), $3, $4, $5
-)
-RETURNING "id";`
+VALUES($1, $2, $3, true, NOW(), $4, $5, $6);`
 
-	err = t.QueryRow(ctx, query, guildId, userId, isThread, panelId, model.TicketStatusOpen).Scan(&id)
-	return
+	if _, err := tx.Exec(ctx, query, id, guildId, userId, isThread, panelId, model.TicketStatusOpen); err != nil {
+		return 0, err
+	}
+
+	return id, nil
 }
 
 func (t *TicketTable) SetChannelId(ctx context.Context, guildId uint64, ticketId int, channelId uint64) (err error) {
@@ -125,6 +186,7 @@ WHERE "guild_id" = $3 AND "id" = $4;`
 	return
 }
 
+// Get returns ErrNotFound if the ticket does not exist
 func (t *TicketTable) Get(ctx context.Context, ticketId int, guildId uint64) (ticket Ticket, e error) {
 	query := `
 SELECT id, guild_id, channel_id, user_id, open, open_time, welcome_message_id, panel_id, has_transcript, close_time, is_thread, join_message_id, notes_thread_id, status
@@ -146,7 +208,7 @@ WHERE "id" = $1 AND "guild_id" = $2;`
 		&ticket.JoinMessageId,
 		&ticket.NotesThreadId,
 		&ticket.Status,
-	); err != nil && err != pgx.ErrNoRows {
+	); err != nil {
 		e = err
 	}
 	return
@@ -163,6 +225,8 @@ func (t *TicketTable) GetByOptions(ctx context.Context, options TicketQueryOptio
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		err = rows.Scan(
@@ -214,71 +278,72 @@ FROM tickets`
 		query += " INNER JOIN service_ratings ON tickets.guild_id = service_ratings.guild_id AND tickets.id = service_ratings.ticket_id "
 	}
 
-	if !o.HasWhereClause() {
-		query += " WHERE "
-	}
-
-	var needsAnd bool
+	var conditions []string
 
 	if o.Id != 0 {
 		args = append(args, o.Id)
-		query += fmt.Sprintf(`tickets.id = $%d`, len(args))
-		needsAnd = true
+		conditions = append(conditions, fmt.Sprintf(`tickets.id = $%d`, len(args)))
 	}
 
 	if o.GuildId != 0 {
-		if needsAnd {
-			query += " AND "
-		}
-
 		args = append(args, o.GuildId)
-		query += fmt.Sprintf(`tickets.guild_id = $%d`, len(args))
-		needsAnd = true
+		conditions = append(conditions, fmt.Sprintf(`tickets.guild_id = $%d`, len(args)))
 	}
 
 	if len(o.UserIds) > 0 {
-		if needsAnd {
-			query += " AND "
-		}
-
 		userIdArray := &pgtype.Int8Array{}
 		if err := userIdArray.Set(o.UserIds); err != nil {
 			return "", nil, err
 		}
 
 		args = append(args, userIdArray)
-		query += fmt.Sprintf(`tickets.user_id = ANY($%d)`, len(args))
-		needsAnd = true
+		conditions = append(conditions, fmt.Sprintf(`tickets.user_id = ANY($%d)`, len(args)))
 	}
 
 	if o.Open != nil {
-		if needsAnd {
-			query += " AND "
-		}
-
 		args = append(args, *o.Open)
-		query += fmt.Sprintf(`tickets.open = $%d`, len(args))
-		needsAnd = true
+		conditions = append(conditions, fmt.Sprintf(`tickets.open = $%d`, len(args)))
 	}
 
 	if o.PanelId > 0 {
-		if needsAnd {
-			query += " AND "
-		}
-
 		args = append(args, o.PanelId)
-		query += fmt.Sprintf(`tickets.panel_id = $%d`, len(args))
-		needsAnd = true
+		conditions = append(conditions, fmt.Sprintf(`tickets.panel_id = $%d`, len(args)))
 	}
 
 	if o.Rating > 0 {
-		if needsAnd {
-			query += " AND "
+		args = append(args, o.Rating)
+		conditions = append(conditions, fmt.Sprintf(`service_ratings.rating = $%d`, len(args)))
+	}
+
+	if len(o.Priorities) > 0 {
+		priorities := make([]string, len(o.Priorities))
+		for i, priority := range o.Priorities {
+			priorities[i] = string(priority)
 		}
 
-		args = append(args, o.Rating)
-		query += fmt.Sprintf(`service_ratings.rating = $%d`, len(args))
-		needsAnd = true
+		args = append(args, priorities)
+		conditions = append(conditions, fmt.Sprintf(`tickets.priority = ANY($%d::ticket_priority[])`, len(args)))
+	}
+
+	// Tickets must have every label
+	for _, labelId := range o.LabelIds {
+		args = append(args, labelId)
+		conditions = append(conditions, fmt.Sprintf(
+			`EXISTS(SELECT 1 FROM ticket_label_assignments WHERE ticket_label_assignments.guild_id = tickets.guild_id AND ticket_label_assignments.ticket_id = tickets.id AND ticket_label_assignments.label_id = $%d)`,
+			len(args),
+		))
+	}
+
+	for _, value := range o.CustomFields {
+		args = append(args, value.FieldId, value.Text, value.Number, value.Date)
+		conditions = append(conditions, fmt.Sprintf(
+			`EXISTS(SELECT 1 FROM ticket_custom_field_values AS values WHERE values.guild_id = tickets.guild_id AND values.ticket_id = tickets.id AND values.field_id = $%d AND values.value_text IS NOT DISTINCT FROM $%d AND values.value_number IS NOT DISTINCT FROM $%d AND values.value_date IS NOT DISTINCT FROM $%d::date)`,
+			len(args)-3, len(args)-2, len(args)-1, len(args),
+		))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	// Cannot use prepared statement for this value
@@ -300,7 +365,8 @@ FROM tickets`
 	return
 }
 
-func (t *TicketTable) GetByChannel(ctx context.Context, channelId uint64) (Ticket, bool, error) {
+// GetByChannel returns ErrNotFound if there is no ticket in the channel
+func (t *TicketTable) GetByChannel(ctx context.Context, channelId uint64) (Ticket, error) {
 	query := `
 SELECT id, guild_id, channel_id, user_id, open, open_time, welcome_message_id, panel_id, has_transcript, close_time, is_thread, join_message_id, notes_thread_id, status
 FROM tickets
@@ -323,16 +389,13 @@ WHERE "channel_id" = $1;`
 		&ticket.NotesThreadId,
 		&ticket.Status,
 	); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return Ticket{}, false, nil
-		} else {
-			return Ticket{}, false, err
-		}
+		return Ticket{}, err
 	}
 
-	return ticket, true, nil
+	return ticket, nil
 }
 
+// GetByChannelAndGuild returns ErrNotFound if there is no ticket in the channel
 func (t *TicketTable) GetByChannelAndGuild(ctx context.Context, channelId, guildId uint64) (ticket Ticket, e error) {
 	query := `
 SELECT id, guild_id, channel_id, user_id, open, open_time, welcome_message_id, panel_id, has_transcript, close_time, is_thread, join_message_id, notes_thread_id
@@ -353,7 +416,7 @@ WHERE "channel_id" = $1 AND "guild_id" = $2;`
 		&ticket.IsThread,
 		&ticket.JoinMessageId,
 		&ticket.NotesThreadId,
-	); err != nil && err != pgx.ErrNoRows {
+	); err != nil {
 		e = err
 	}
 	return
@@ -366,12 +429,13 @@ FROM tickets
 WHERE "guild_id" = $1 AND "user_id" = $2;`
 
 	rows, err := t.Query(ctx, query, guildId, userId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -421,12 +485,13 @@ FROM tickets
 WHERE "user_id" = $1 AND "open" = true AND "guild_id" = $2;`
 
 	rows, err := t.Query(ctx, query, userId, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -477,7 +542,7 @@ ORDER BY "id" DESC
 LIMIT $4;`
 
 	rows, err := t.Query(ctx, query, userId, guildId, prefix, limit)
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		return nil, err
 	}
 
@@ -523,12 +588,13 @@ LIMIT $4;`
 	}
 
 	rows, err := t.Query(ctx, query, guildId, userIdArray, before, limit)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -581,12 +647,13 @@ LIMIT $4;`
 	}
 
 	rows, err := t.Query(ctx, query, guildId, userIdArray, before, limit)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket TicketWithCloseReason
 		if err := rows.Scan(
@@ -632,12 +699,13 @@ LIMIT $4;`
 	}
 
 	rows, err := t.Query(ctx, query, guildId, userIdArray, after, limit)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket TicketWithCloseReason
 		if err := rows.Scan(
@@ -675,12 +743,13 @@ WHERE "guild_id" = $1 AND "open" = true
 ORDER BY id DESC;`
 
 	rows, err := t.Query(ctx, query, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -711,7 +780,10 @@ ORDER BY id DESC;`
 type TicketWithMetadata struct {
 	Ticket
 	TicketLastMessage
-	ClaimedBy *uint64 `json:"claimed_by"`
+	ClaimedBy    *uint64            `json:"claimed_by"`
+	Priority     *TicketPriority    `json:"priority"`
+	LabelIds     []int              `json:"label_ids"`
+	CustomFields []CustomFieldValue `json:"custom_fields"`
 }
 
 func (t *TicketTable) GetGuildOpenTicketsWithMetadata(ctx context.Context, guildId uint64) ([]TicketWithMetadata, error) {
@@ -719,7 +791,9 @@ func (t *TicketTable) GetGuildOpenTicketsWithMetadata(ctx context.Context, guild
 SELECT 
     tickets.id, tickets.guild_id, tickets.channel_id, tickets.user_id, tickets.open, tickets.open_time, tickets.welcome_message_id, tickets.panel_id, tickets.has_transcript, tickets.close_time, tickets.is_thread, tickets.join_message_id, tickets.notes_thread_id, tickets.status,
     ticket_claims.user_id,
-    ticket_last_message.last_message_id, ticket_last_message.last_message_time, ticket_last_message.user_id, ticket_last_message.user_is_staff
+    ticket_last_message.last_message_id, ticket_last_message.last_message_time, ticket_last_message.user_id, ticket_last_message.user_is_staff,
+    tickets.priority,
+    ARRAY(SELECT label_id FROM ticket_label_assignments WHERE ticket_label_assignments.guild_id = tickets.guild_id AND ticket_label_assignments.ticket_id = tickets.id ORDER BY label_id)
 FROM tickets
 LEFT OUTER JOIN ticket_claims ON tickets.id = ticket_claims.ticket_id AND tickets.guild_id = ticket_claims.guild_id
 LEFT OUTER JOIN ticket_last_message ON tickets.id = ticket_last_message.ticket_id AND tickets.guild_id = ticket_last_message.guild_id
@@ -756,16 +830,67 @@ ORDER BY tickets.id DESC;`
 			&ticket.LastMessageTime,
 			&ticket.TicketLastMessage.UserId,
 			&ticket.TicketLastMessage.UserIsStaff,
+			&ticket.Priority,
+			&ticket.LabelIds,
 		); err != nil {
 			return nil, err
 		}
 
+		ticket.CustomFields = make([]CustomFieldValue, 0)
 		tickets = append(tickets, ticket)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := t.populateCustomFields(ctx, guildId, tickets); err != nil {
+		return nil, err
+	}
+
 	return tickets, nil
 }
 
+// populateCustomFields fetches the custom field values of the guild's open tickets
+func (t *TicketTable) populateCustomFields(ctx context.Context, guildId uint64, tickets []TicketWithMetadata) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	query := `
+SELECT values.ticket_id, values.field_id, values.value_text, values.value_number, values.value_date
+FROM ticket_custom_field_values AS values
+INNER JOIN tickets ON values.guild_id = tickets.guild_id AND values.ticket_id = tickets.id
+WHERE tickets.guild_id = $1 AND tickets.open = true;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	indexes := make(map[int]int, len(tickets))
+	for i, ticket := range tickets {
+		indexes[ticket.Id] = i
+	}
+
+	for rows.Next() {
+		var ticketId int
+		var value CustomFieldValue
+		if err := rows.Scan(&ticketId, &value.FieldId, &value.Text, &value.Number, &value.Date); err != nil {
+			return err
+		}
+
+		// The ticket may have been opened since the first query
+		if i, ok := indexes[ticketId]; ok {
+			tickets[i].CustomFields = append(tickets[i].CustomFields, value)
+		}
+	}
+
+	return rows.Err()
+}
+
 func (t *TicketTable) GetGuildOpenTicketsExcludeThreads(ctx context.Context, guildId uint64) (tickets []Ticket, e error) {
 	query := `
 SELECT id, guild_id, channel_id, user_id, open, open_time, welcome_message_id, panel_id, has_transcript, close_time, is_thread, join_message_id, notes_thread_id, status
@@ -774,12 +899,13 @@ WHERE "guild_id" = $1 AND "open" = true AND "is_thread" = false
 ORDER BY id DESC;`
 
 	rows, err := t.Query(ctx, query, guildId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -819,12 +945,13 @@ ORDER BY "id" DESC LIMIT $2;`
 	}
 
 	rows, err := t.Query(ctx, query, guildId, limit, before)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket Ticket
 		if err := rows.Scan(
@@ -866,12 +993,13 @@ ORDER BY tickets.id DESC LIMIT $2;`
 	}
 
 	rows, err := t.Query(ctx, query, guildId, limit, before)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket TicketWithCloseReason
 		if err := rows.Scan(
@@ -911,12 +1039,13 @@ WHERE tickets.guild_id = $1 AND tickets.open = false AND tickets.id > $3
 ORDER BY tickets.id ASC LIMIT $2;`
 
 	rows, err := t.Query(ctx, query, guildId, limit, after)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var ticket TicketWithCloseReason
 		if err := rows.Scan(
@@ -964,11 +1093,12 @@ ORDER BY "id" DESC LIMIT $3;
 	}
 
 	rows, err := t.Query(ctx, query, guildId, userIds, limit, before)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	var tickets []Ticket
 	for rows.Next() {
 		var ticket Ticket
@@ -1018,9 +1148,59 @@ func (t *TicketTable) GetTotalTicketCount(ctx context.Context, guildId uint64) (
 	return
 }
 
+// ticketResolutionPeriods selects the start and end of each completed open/close cycle of tickets opened within the
+// interval. Cycles that ended in the ticket being reopened are taken from the reopen history, and the current cycle of
+// closed tickets runs from the last reopen, or the open time if never reopened, to the close time.
+const ticketResolutionPeriods = `
+SELECT ticket_reopens.opened_at, ticket_reopens.closed_at
+FROM ticket_reopens
+INNER JOIN tickets ON tickets.guild_id = ticket_reopens.guild_id AND tickets.id = ticket_reopens.ticket_id
+WHERE tickets.guild_id = $1 AND tickets.open_time > NOW() - $2::interval
+UNION ALL
+SELECT ` + ticketCycleStart + `, tickets.close_time
+FROM tickets
+WHERE tickets.guild_id = $1 AND tickets.open = false AND tickets.close_time IS NOT NULL AND tickets.open_time > NOW() - $2::interval`
+
+// GetAverageResolutionTime returns the average time between tickets being opened, or reopened, and closed, for tickets
+// opened within the interval. Each open/close cycle of a reopened ticket is counted separately. It returns nil if no
+// tickets have been closed.
+func (t *TicketTable) GetAverageResolutionTime(ctx context.Context, guildId uint64, interval time.Duration) (*time.Duration, error) {
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT AVG(periods.closed_at - periods.opened_at) FROM (` + ticketResolutionPeriods + `) AS periods(opened_at, closed_at);`
+
+	var average *time.Duration
+	if err := t.QueryRow(ctx, query, guildId, parsedInterval).Scan(&average); err != nil {
+		return nil, err
+	}
+
+	return average, nil
+}
+
+// GetAverageResolutionBusinessTime returns the average time between tickets being opened, or reopened, and closed,
+// counting only the time within the calendar's business hours, for tickets opened within the interval. Each open/close
+// cycle of a reopened ticket is counted separately. It returns nil if no tickets have been closed.
+func (t *TicketTable) GetAverageResolutionBusinessTime(ctx context.Context, guildId uint64, calendar BusinessCalendar, interval time.Duration) (*time.Duration, error) {
+	parsedInterval, err := toInterval(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	return averageBusinessDuration(ctx, t.Pool, calendar, ticketResolutionPeriods+";", guildId, parsedInterval)
+}
+
+const ticketsClose = `UPDATE tickets SET "open"=false, "close_time"=NOW(), "status"='CLOSED' WHERE "id"=$1 AND "guild_id"=$2;`
+
 func (t *TicketTable) Close(ctx context.Context, ticketId int, guildId uint64) (err error) {
-	query := `UPDATE tickets SET "open"=false, "close_time"=NOW(), "status"='CLOSED' WHERE "id"=$1 AND "guild_id"=$2;`
-	_, err = t.Exec(ctx, query, ticketId, guildId)
+	_, err = t.Exec(ctx, ticketsClose, ticketId, guildId)
+	return
+}
+
+func (t *TicketTable) CloseTx(ctx context.Context, tx pgx.Tx, ticketId int, guildId uint64) (err error) {
+	_, err = tx.Exec(ctx, ticketsClose, ticketId, guildId)
 	return
 }
 
@@ -1042,6 +1222,7 @@ func (t *TicketTable) SetPanelId(ctx context.Context, guildId uint64, ticketId,
 	return
 }
 
+// Deprecated: use TicketReopens.Reopen, which also archives the ticket's close reason and rating and resets its status
 func (t *TicketTable) SetOpen(ctx context.Context, guildId uint64, ticketId int) (err error) {
 	query := `UPDATE tickets SET "open" = TRUE, "close_time" = NULL WHERE "guild_id" = $1 AND "id" = $2;`
 	_, err = t.Exec(ctx, query, guildId, ticketId)
@@ -1061,9 +1242,20 @@ func (t *TicketTable) SetNotesThreadId(ctx context.Context, guildId uint64, tick
 	return err
 }
 
+func (t *TicketTable) SetPriority(ctx context.Context, guildId uint64, ticketId int, priority *TicketPriority) error {
+	query := `UPDATE tickets SET "priority" = $1 WHERE "guild_id" = $2 AND "id" = $3;`
+	_, err := t.Exec(ctx, query, priority, guildId, ticketId)
+	return err
+}
+
+const ticketsSetStatus = `UPDATE tickets SET "status" = $3 WHERE "guild_id" = $1 AND "id" = $2;`
+
 func (t *TicketTable) SetStatus(ctx context.Context, guildId uint64, ticketId int, status model.TicketStatus) error {
-	query := `UPDATE tickets SET "status" = $3 WHERE "guild_id" = $1 AND "id" = $2;`
+	_, err := t.Exec(ctx, ticketsSetStatus, guildId, ticketId, status)
+	return err
+}
 
-	_, err := t.Exec(ctx, query, guildId, ticketId, status)
+func (t *TicketTable) SetStatusTx(ctx context.Context, tx pgx.Tx, guildId uint64, ticketId int, status model.TicketStatus) error {
+	_, err := tx.Exec(ctx, ticketsSetStatus, guildId, ticketId, status)
 	return err
 }
@@ -4,14 +4,13 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type UsedKeys struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newUsedKeys(db *pgxpool.Pool) *UsedKeys {
+func newUsedKeys(db *Pool) *UsedKeys {
 	return &UsedKeys{
 		db,
 	}
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type UsersCanClose struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newUsersCanClose(db *pgxpool.Pool) *UsersCanClose {
+func newUsersCanClose(db *Pool) *UsersCanClose {
 	return &UsersCanClose{
 		db,
 	}
@@ -27,7 +26,7 @@ CREATE TABLE IF NOT EXISTS users_can_close(
 
 func (u *UsersCanClose) Get(ctx context.Context, guildId uint64) (usersCanClose bool, e error) {
 	if err := u.QueryRow(ctx, `SELECT "users_can_close" from users_can_close WHERE "guild_id" = $1;`, guildId).Scan(&usersCanClose); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			usersCanClose = true
 		} else {
 			e = err
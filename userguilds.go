@@ -4,7 +4,6 @@ import (
 	"context"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type UserGuild struct {
@@ -16,10 +15,10 @@ type UserGuild struct {
 }
 
 type UserGuildsTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newUserGuildsTable(db *pgxpool.Pool) *UserGuildsTable {
+func newUserGuildsTable(db *Pool) *UserGuildsTable {
 	return &UserGuildsTable{
 		db,
 	}
@@ -43,12 +42,13 @@ func (u *UserGuildsTable) Get(ctx context.Context, userId uint64) (guilds []User
 	query := `SELECT "guild_id", "name", "owner", "permissions", "icon" FROM user_guilds WHERE "user_id" = $1;`
 
 	rows, err := u.Query(ctx, query, userId)
-	defer rows.Close()
-	if err != nil && err != pgx.ErrNoRows {
+	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var guild UserGuild
 		if err := rows.Scan(&guild.GuildId, &guild.Name, &guild.Owner, &guild.UserPermissions, &guild.Icon); err != nil {
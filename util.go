@@ -4,7 +4,6 @@ import (
 	"context"
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
@@ -13,7 +12,7 @@ func toInterval(duration time.Duration) (interval pgtype.Interval, err error) {
 	return
 }
 
-func transact(ctx context.Context, pool *pgxpool.Pool, statements ...string) (pgx.Tx, error) {
+func transact(ctx context.Context, pool *Pool, statements ...string) (pgx.Tx, error) {
 	tx, err := pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return tx, err
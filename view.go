@@ -1,7 +1,164 @@
 package database
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"strings"
+	"time"
+)
 
 type View interface {
+	Table
+	Name() string
+	RefreshInterval() time.Duration
 	Refresh(ctx context.Context) error
 }
+
+type ViewIndex struct {
+	// Suffix is appended to the view name to produce the index name, e.g. "integration_id_key"
+	Suffix  string
+	Columns []string
+	Unique  bool
+}
+
+// MaterializedView is a reusable definition of a materialized view. The view is created by Schema, and is kept up to
+// date by calling Refresh, usually from a ViewScheduler.
+type MaterializedView struct {
+	*Pool
+	name            string
+	query           string
+	indexes         []ViewIndex
+	refreshInterval time.Duration
+	// concurrent views are refreshed with REFRESH MATERIALIZED VIEW CONCURRENTLY, which does not block readers, but
+	// requires at least one unique index. Otherwise, the view is rebuilt under a temporary name and swapped in.
+	concurrent bool
+	// afterRefresh statements are executed in the same transaction as the refresh
+	afterRefresh []string
+}
+
+var ErrViewRefreshInProgress = errors.New("view refresh already in progress")
+
+func newMaterializedView(
+	db *Pool,
+	name, query string,
+	refreshInterval time.Duration,
+	concurrent bool,
+	indexes ...ViewIndex,
+) *MaterializedView {
+	return &MaterializedView{
+		Pool:            db,
+		name:            name,
+		query:           query,
+		indexes:         indexes,
+		refreshInterval: refreshInterval,
+		concurrent:      concurrent,
+	}
+}
+
+func (v *MaterializedView) Name() string {
+	return v.name
+}
+
+func (v *MaterializedView) RefreshInterval() time.Duration {
+	return v.refreshInterval
+}
+
+func (v *MaterializedView) Schema() string {
+	return strings.Join(v.createStatements(v.name), "\n")
+}
+
+func (v *MaterializedView) createStatements(viewName string) []string {
+	statements := slice(fmt.Sprintf(`
+CREATE MATERIALIZED VIEW IF NOT EXISTS %s
+AS
+%s
+WITH DATA;
+`, viewName, v.query))
+
+	for _, index := range v.indexes {
+		unique := ""
+		if index.Unique {
+			unique = "UNIQUE "
+		}
+
+		statements = append(statements, fmt.Sprintf(
+			"CREATE %sINDEX IF NOT EXISTS %s_%s ON %s(%s);",
+			unique, viewName, index.Suffix, viewName, strings.Join(index.Columns, ", "),
+		))
+	}
+
+	return statements
+}
+
+func (v *MaterializedView) hasUniqueIndex() bool {
+	for _, index := range v.indexes {
+		if index.Unique {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (v *MaterializedView) refreshStatements() ([]string, error) {
+	if v.concurrent {
+		if !v.hasUniqueIndex() {
+			return nil, fmt.Errorf("view %s cannot be refreshed concurrently without a unique index", v.name)
+		}
+
+		return slice(fmt.Sprintf("REFRESH MATERIALIZED VIEW CONCURRENTLY %s;", v.name)), nil
+	}
+
+	tmpName := v.name + "_new"
+
+	statements := slice(fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s;", tmpName))
+	statements = append(statements, v.createStatements(tmpName)...)
+	statements = append(statements,
+		fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s;", v.name),
+		fmt.Sprintf("ALTER MATERIALIZED VIEW %s RENAME TO %s;", tmpName, v.name),
+	)
+
+	for _, index := range v.indexes {
+		statements = append(statements, fmt.Sprintf(
+			"ALTER INDEX %s_%s RENAME TO %s_%s;", tmpName, index.Suffix, v.name, index.Suffix,
+		))
+	}
+
+	return statements, nil
+}
+
+// Refresh brings the view up to date. A transaction scoped advisory lock is held while refreshing, so if another
+// process is already refreshing the view, ErrViewRefreshInProgress is returned.
+func (v *MaterializedView) Refresh(ctx context.Context) error {
+	statements, err := v.refreshStatements()
+	if err != nil {
+		return err
+	}
+
+	tx, err := v.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtext($1));`, v.name).Scan(&locked); err != nil {
+		return err
+	}
+
+	if !locked {
+		return ErrViewRefreshInProgress
+	}
+
+	statements = append(statements, v.afterRefresh...)
+	for _, statement := range statements {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
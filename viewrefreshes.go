@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	_ "embed"
+	"time"
+)
+
+// ViewRefreshes records the outcome of the most recent refresh of each materialized view, for scheduling and
+// monitoring purposes.
+type ViewRefreshes struct {
+	*Pool
+}
+
+type ViewRefreshStatus struct {
+	ViewName      string        `json:"view_name"`
+	LastAttemptAt time.Time     `json:"last_attempt_at"`
+	LastSuccessAt *time.Time    `json:"last_success_at"`
+	LastDuration  time.Duration `json:"last_duration"`
+	LastError     *string       `json:"last_error"`
+	SuccessCount  int64         `json:"success_count"`
+	FailureCount  int64         `json:"failure_count"`
+}
+
+var (
+	//go:embed sql/view_refreshes/schema.sql
+	viewRefreshesSchema string
+
+	//go:embed sql/view_refreshes/record_success.sql
+	viewRefreshesRecordSuccess string
+
+	//go:embed sql/view_refreshes/record_failure.sql
+	viewRefreshesRecordFailure string
+
+	//go:embed sql/view_refreshes/get_all.sql
+	viewRefreshesGetAll string
+)
+
+func newViewRefreshes(db *Pool) *ViewRefreshes {
+	return &ViewRefreshes{
+		db,
+	}
+}
+
+func (ViewRefreshes) Schema() string {
+	return viewRefreshesSchema
+}
+
+func (r *ViewRefreshes) RecordSuccess(ctx context.Context, viewName string, startedAt time.Time, duration time.Duration) error {
+	_, err := r.Exec(ctx, viewRefreshesRecordSuccess, viewName, startedAt, duration)
+	return err
+}
+
+func (r *ViewRefreshes) RecordFailure(ctx context.Context, viewName string, startedAt time.Time, duration time.Duration, refreshErr error) error {
+	_, err := r.Exec(ctx, viewRefreshesRecordFailure, viewName, startedAt, duration, refreshErr.Error())
+	return err
+}
+
+func (r *ViewRefreshes) GetAll(ctx context.Context) ([]ViewRefreshStatus, error) {
+	rows, err := r.Query(ctx, viewRefreshesGetAll)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var statuses []ViewRefreshStatus
+	for rows.Next() {
+		var status ViewRefreshStatus
+		if err := rows.Scan(
+			&status.ViewName,
+			&status.LastAttemptAt,
+			&status.LastSuccessAt,
+			&status.LastDuration,
+			&status.LastError,
+			&status.SuccessCount,
+			&status.FailureCount,
+		); err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, rows.Err()
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// viewRetryInterval is the maximum time to wait before retrying a view that failed to refresh
+const viewRetryInterval = time.Minute * 5
+
+// ViewScheduler refreshes each view on its own interval, recording the outcome of each refresh in ViewRefreshes.
+type ViewScheduler struct {
+	views     []View
+	refreshes *ViewRefreshes
+
+	// OnRefresh, if set, is called after every refresh attempt. err is nil if the refresh succeeded.
+	OnRefresh func(view View, duration time.Duration, err error)
+
+	// ShutdownGracePeriod is how long an in-progress refresh is allowed to continue for after the context passed to
+	// Run or RefreshAll is cancelled. If zero, the refresh is cancelled immediately.
+	ShutdownGracePeriod time.Duration
+}
+
+func NewViewScheduler(refreshes *ViewRefreshes, views ...View) *ViewScheduler {
+	return &ViewScheduler{
+		views:     views,
+		refreshes: refreshes,
+	}
+}
+
+func (d *Database) NewViewScheduler() *ViewScheduler {
+	return NewViewScheduler(d.ViewRefreshes, d.Views()...)
+}
+
+// RefreshAll refreshes every view once, regardless of when it was last refreshed
+func (s *ViewScheduler) RefreshAll(ctx context.Context) error {
+	var errs []error
+	for _, view := range s.views {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		if err := s.refresh(ctx, view); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run refreshes views as they become due, until ctx is cancelled. Views that have not been refreshed within their
+// interval, according to ViewRefreshes, are refreshed immediately.
+func (s *ViewScheduler) Run(ctx context.Context) error {
+	if len(s.views) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	nextRefresh, err := s.initialSchedule(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		next := s.views[0]
+		for _, view := range s.views[1:] {
+			if nextRefresh[view.Name()].Before(nextRefresh[next.Name()]) {
+				next = view
+			}
+		}
+
+		timer := time.NewTimer(time.Until(nextRefresh[next.Name()]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := s.refresh(ctx, next); err != nil {
+			retryAfter := next.RefreshInterval()
+			if retryAfter > viewRetryInterval {
+				retryAfter = viewRetryInterval
+			}
+
+			nextRefresh[next.Name()] = time.Now().Add(retryAfter)
+		} else {
+			nextRefresh[next.Name()] = time.Now().Add(next.RefreshInterval())
+		}
+	}
+}
+
+func (s *ViewScheduler) initialSchedule(ctx context.Context) (map[string]time.Time, error) {
+	statuses, err := s.refreshes.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastSuccess := make(map[string]time.Time)
+	for _, status := range statuses {
+		if status.LastSuccessAt != nil {
+			lastSuccess[status.ViewName] = *status.LastSuccessAt
+		}
+	}
+
+	now := time.Now()
+	schedule := make(map[string]time.Time)
+	for _, view := range s.views {
+		if last, ok := lastSuccess[view.Name()]; ok {
+			schedule[view.Name()] = last.Add(view.RefreshInterval())
+		} else {
+			schedule[view.Name()] = now
+		}
+	}
+
+	return schedule, nil
+}
+
+// refreshContext returns a context that is only cancelled once ShutdownGracePeriod has elapsed after ctx is cancelled
+func (s *ViewScheduler) refreshContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.ShutdownGracePeriod <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	refreshCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(s.ShutdownGracePeriod, cancel)
+	})
+
+	return refreshCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+func (s *ViewScheduler) refresh(ctx context.Context, view View) error {
+	ctx, cancel := s.refreshContext(ctx)
+	defer cancel()
+
+	startedAt := time.Now()
+	refreshErr := view.Refresh(ctx)
+	duration := time.Since(startedAt)
+
+	if s.OnRefresh != nil {
+		s.OnRefresh(view, duration, refreshErr)
+	}
+
+	// Another process is refreshing the view, and will record the outcome itself
+	if errors.Is(refreshErr, ErrViewRefreshInProgress) {
+		return refreshErr
+	}
+
+	var recordErr error
+	if refreshErr == nil {
+		recordErr = s.refreshes.RecordSuccess(ctx, view.Name(), startedAt, duration)
+	} else {
+		recordErr = s.refreshes.RecordFailure(ctx, view.Name(), startedAt, duration, refreshErr)
+	}
+
+	return errors.Join(refreshErr, recordErr)
+}
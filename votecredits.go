@@ -5,11 +5,10 @@ import (
 	_ "embed"
 	"errors"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type VoteCredits struct {
-	*pgxpool.Pool
+	*Pool
 }
 
 var (
@@ -26,7 +25,7 @@ var (
 	voteCreditsDelete string
 )
 
-func newVoteCreditsTable(db *pgxpool.Pool) *VoteCredits {
+func newVoteCreditsTable(db *Pool) *VoteCredits {
 	return &VoteCredits{
 		db,
 	}
@@ -39,7 +38,7 @@ func (VoteCredits) Schema() string {
 func (v *VoteCredits) Get(ctx context.Context, tx pgx.Tx, userId uint64) (int, error) {
 	var credits int
 	if err := tx.QueryRow(ctx, voteCreditsGet, userId).Scan(&credits); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return 0, nil
 		}
 
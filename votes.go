@@ -2,17 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type Votes struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newVotes(db *pgxpool.Pool) *Votes {
+func newVotes(db *Pool) *Votes {
 	return &Votes{
 		db,
 	}
@@ -30,7 +29,7 @@ CREATE TABLE IF NOT EXISTS votes(
 func (v *Votes) Get(ctx context.Context, userId uint64) (voteTime time.Time, e error) {
 	query := `SELECT "vote_time" from votes WHERE "user_id" = $1`
 
-	if err := v.QueryRow(ctx, query, userId).Scan(&voteTime); err != nil && err != pgx.ErrNoRows {
+	if err := v.QueryRow(ctx, query, userId).Scan(&voteTime); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -2,8 +2,7 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type Webhook struct {
@@ -12,10 +11,10 @@ type Webhook struct {
 }
 
 type WebhookTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWebhookTable(db *pgxpool.Pool) *WebhookTable {
+func newWebhookTable(db *Pool) *WebhookTable {
 	return &WebhookTable{
 		db,
 	}
@@ -35,7 +34,7 @@ CREATE TABLE IF NOT EXISTS webhooks(
 
 func (w *WebhookTable) Get(ctx context.Context, guildId uint64, ticketId int) (webhook Webhook, e error) {
 	query := `SELECT "webhook_id", "webhook_token" from webhooks WHERE "guild_id"=$1 AND "ticket_id"=$2;`
-	if err := w.QueryRow(ctx, query, guildId, ticketId).Scan(&webhook.Id, &webhook.Token); err != nil && err != pgx.ErrNoRows {
+	if err := w.QueryRow(ctx, query, guildId, ticketId).Scan(&webhook.Id, &webhook.Token); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type WelcomeMessages struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWelcomeMessages(db *pgxpool.Pool) *WelcomeMessages {
+func newWelcomeMessages(db *Pool) *WelcomeMessages {
 	return &WelcomeMessages{
 		db,
 	}
@@ -28,7 +27,7 @@ CREATE TABLE IF NOT EXISTS welcome_messages(
 func (w *WelcomeMessages) Get(ctx context.Context, guildId uint64) (welcomeMessage string, e error) {
 	query := `SELECT "welcome_message" from welcome_messages WHERE "guild_id" = $1;`
 
-	if err := w.QueryRow(ctx, query, guildId).Scan(&welcomeMessage); err != nil && err != pgx.ErrNoRows {
+	if err := w.QueryRow(ctx, query, guildId).Scan(&welcomeMessage); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 
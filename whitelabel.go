@@ -3,8 +3,6 @@ package database
 import (
 	"context"
 	"errors"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type WhitelabelBot struct {
@@ -15,10 +13,10 @@ type WhitelabelBot struct {
 }
 
 type WhitelabelBotTable struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWhitelabelBotTable(db *pgxpool.Pool) *WhitelabelBotTable {
+func newWhitelabelBotTable(db *Pool) *WhitelabelBotTable {
 	return &WhitelabelBotTable{
 		db,
 	}
@@ -41,7 +39,7 @@ func (w *WhitelabelBotTable) GetByUserId(ctx context.Context, userId uint64) (Wh
 	query := `SELECT "user_id", "bot_id", "public_key", "token" FROM whitelabel WHERE "user_id" = $1;`
 
 	var bot WhitelabelBot
-	if err := w.QueryRow(ctx, query, userId).Scan(&bot.UserId, &bot.BotId, &bot.PublicKey, &bot.Token); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+	if err := w.QueryRow(ctx, query, userId).Scan(&bot.UserId, &bot.BotId, &bot.PublicKey, &bot.Token); err != nil && !errors.Is(err, ErrNotFound) {
 		return WhitelabelBot{}, err
 	}
 
@@ -52,7 +50,7 @@ func (w *WhitelabelBotTable) GetByBotId(ctx context.Context, botId uint64) (Whit
 	query := `SELECT "user_id", "bot_id", "public_key", "token" FROM whitelabel WHERE "bot_id" = $1;`
 
 	var bot WhitelabelBot
-	if err := w.QueryRow(ctx, query, botId).Scan(&bot.UserId, &bot.BotId, &bot.PublicKey, &bot.Token); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+	if err := w.QueryRow(ctx, query, botId).Scan(&bot.UserId, &bot.BotId, &bot.PublicKey, &bot.Token); err != nil && !errors.Is(err, ErrNotFound) {
 		return WhitelabelBot{}, err
 	}
 
@@ -73,7 +71,7 @@ func (w *WhitelabelBotTable) Delete(ctx context.Context, userId uint64) (*uint64
 
 	var botId uint64
 	if err := w.QueryRow(ctx, query, userId).Scan(&botId); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, ErrNotFound) {
 			return nil, nil
 		}
 
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type WhitelabelErrors struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWhitelabelErrors(db *pgxpool.Pool) *WhitelabelErrors {
+func newWhitelabelErrors(db *Pool) *WhitelabelErrors {
 	return &WhitelabelErrors{
 		db,
 	}
@@ -37,12 +36,13 @@ func (w *WhitelabelErrors) GetRecent(ctx context.Context, userId uint64, limit i
 	query := `SELECT "error", "error_time" FROM whitelabel_errors WHERE "user_id" = $1 ORDER BY "error_id" DESC LIMIT $2;`
 
 	rows, err := w.Query(ctx, query, userId, limit)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var error WhitelabelError
 		if e = rows.Scan(&error.Message, &error.Time); e != nil {
@@ -2,15 +2,14 @@ package database
 
 import (
 	"context"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
+	"errors"
 )
 
 type WhitelabelGuilds struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWhitelabelGuilds(db *pgxpool.Pool) *WhitelabelGuilds {
+func newWhitelabelGuilds(db *Pool) *WhitelabelGuilds {
 	return &WhitelabelGuilds{
 		db,
 	}
@@ -30,12 +29,13 @@ func (w *WhitelabelGuilds) GetGuilds(ctx context.Context, botId uint64) (guilds
 	query := `SELECT "guild_id" from whitelabel_guilds WHERE "bot_id"=$1;`
 
 	rows, err := w.Query(ctx, query, botId)
-	defer rows.Close()
 	if err != nil {
 		e = err
 		return
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var id uint64
 		if err := rows.Scan(&id); err != nil {
@@ -52,7 +52,7 @@ func (w *WhitelabelGuilds) GetBotByGuild(ctx context.Context, guildId uint64) (b
 	query := `SELECT "bot_id" from whitelabel_guilds WHERE "guild_id"=$1 LIMIT 1;`
 
 	if err := w.QueryRow(ctx, query, guildId).Scan(&botId); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			found = false
 		} else {
 			e = err
@@ -2,16 +2,15 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type WhitelabelStatuses struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWhitelabelStatuses(db *pgxpool.Pool) *WhitelabelStatuses {
+func newWhitelabelStatuses(db *Pool) *WhitelabelStatuses {
 	return &WhitelabelStatuses{
 		db,
 	}
@@ -36,7 +35,7 @@ func (w *WhitelabelStatuses) Get(ctx context.Context, botId uint64) (string, int
 	var status string
 	var statusType int16
 	if err := w.QueryRow(ctx, query, botId).Scan(&status, &statusType); err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, ErrNotFound) {
 			return "", 0, false, nil
 		} else {
 			return "", 0, false, err
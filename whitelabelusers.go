@@ -2,17 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/jackc/pgx/pgtype"
-	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 	"time"
 )
 
 type WhitelabelUsers struct {
-	*pgxpool.Pool
+	*Pool
 }
 
-func newWhitelabelUsers(db *pgxpool.Pool) *WhitelabelUsers {
+func newWhitelabelUsers(db *Pool) *WhitelabelUsers {
 	return &WhitelabelUsers{
 		db,
 	}
@@ -59,7 +58,7 @@ SELECT EXISTS(
 }
 
 func (p *WhitelabelUsers) GetExpiry(ctx context.Context, userId uint64) (expiry time.Time, e error) {
-	if err := p.QueryRow(ctx, `SELECT "expiry" from whitelabel_users WHERE "user_id" = $1;`, userId).Scan(&expiry); err != nil && err != pgx.ErrNoRows {
+	if err := p.QueryRow(ctx, `SELECT "expiry" from whitelabel_users WHERE "user_id" = $1;`, userId).Scan(&expiry); err != nil && !errors.Is(err, ErrNotFound) {
 		e = err
 	}
 